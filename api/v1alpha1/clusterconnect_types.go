@@ -30,7 +30,7 @@ const (
 	TopologyReconciledCondition = "TopologyReconciled"
 
 	// KubeconfigReadyCondition reports if the kubeconfig Secret is ready.
-	// Note: This condition is valid only when CAPI ClusterRef is set.
+	// Note: This condition is valid only when CAPI ClusterRef, KubeconfigRef, or both ServerCertRef and ClientCertRef are set.
 	KubeconfigReadyCondition = "KubeconfigReady"
 
 	// ReadyReason applies to a condition surfacing object readiness.
@@ -41,6 +41,14 @@ const (
 
 	// ReadyUnknownReason applies to a condition surfacing object readiness unknown.
 	ReadyUnknownReason = "ReadyUnknown"
+
+	// KubeconfigCorrectedReason is used when the kubeconfig Secret had drifted from the gateway endpoint,
+	// e.g. after Cluster-API regenerated it, and has been corrected.
+	KubeconfigCorrectedReason = "KubeconfigCorrected"
+
+	// KubeconfigDriftedReason is used when the kubeconfig Secret has drifted from the gateway endpoint
+	// and its correction is delayed, as it was corrected shortly before.
+	KubeconfigDriftedReason = "KubeconfigDrifted"
 )
 
 // ConnectionProbe condition and corresponding reasons.
@@ -54,6 +62,29 @@ const (
 	ConnectionProbeSucceededReason = "ProbeSucceeded"
 )
 
+// Suspended condition and corresponding reasons.
+const (
+	// SuspendedCondition reports if the access to the cluster through the connection gateway is suspended.
+	// Note: This condition is set only once the ClusterConnect has been suspended.
+	SuspendedCondition = "Suspended"
+
+	// SuspendedReason is used when spec.suspended is set and the connect-agent is rejected by the gateway.
+	SuspendedReason = "Suspended"
+
+	// ResumedReason is used when spec.suspended is cleared and the access is restored.
+	ResumedReason = "Resumed"
+)
+
+const (
+	// RotateAgentTokenAnnotation forces an immediate rotation of the agent token, e.g. after a suspected leak.
+	// The previous token is revoked without grace period and the annotation is removed once the token is rotated.
+	RotateAgentTokenAnnotation = "cluster.edge-orchestrator.intel.com/rotate-agent-token"
+
+	// KubeconfigCorrectedAtAnnotation records the time the kubeconfig Secret was last corrected after it drifted
+	// from the gateway endpoint, to delay the next correction if Cluster-API keeps overwriting the Secret.
+	KubeconfigCorrectedAtAnnotation = "cluster.edge-orchestrator.intel.com/kubeconfig-corrected-at"
+)
+
 // ClusterConnectSpec defines the desired state of ClusterConnect.
 type ClusterConnectSpec struct {
 	// ClusterRef is an optional reference to a CAPI provider-specific resource that holds
@@ -71,6 +102,124 @@ type ClusterConnectSpec struct {
 	// The secret format is intended to match the format of the <cluster-name>-cca secret used in cluster-api.
 	// +optional
 	ClientCertRef *corev1.ObjectReference `json:"clientCertRef,omitempty"`
+
+	// KubeconfigRef is an optional reference to a Secret with the kubeconfig for the cluster to connect.
+	// The secret format is intended to match the format of the <cluster-name>-kubeconfig secret used in CAPI.
+	// It is used for clusters that are not managed by CAPI, and takes precedence over ServerCertRef and ClientCertRef
+	// to generate the kubeconfig Secret managed by the controller. It is ignored when ClusterRef is set.
+	// +optional
+	KubeconfigRef *corev1.ObjectReference `json:"kubeconfigRef,omitempty"`
+
+	// Agent is an optional set of overrides for the connect-agent configuration.
+	// Fields that are set here take precedence over the controller-wide defaults.
+	// +optional
+	Agent *AgentSpec `json:"agent,omitempty"`
+
+	// Services is the allowlist of the in-cluster HTTP(S) services of the cluster that can be reached through the
+	// connection gateway at /services/{tunnel_id}/{namespace}/{name}:{port}/, in addition to the kubeapi-server.
+	// +optional
+	Services []ClusterService `json:"services,omitempty"`
+
+	// Suspended temporarily cuts the access to the cluster through the connection gateway.
+	// While set, the connect-agent is rejected and its session is closed. The token and the kubeconfig
+	// are kept, so clearing the field restores the access without re-provisioning.
+	// +optional
+	Suspended bool `json:"suspended,omitempty"`
+}
+
+// AgentSpec defines the per-cluster overrides for the connect-agent Pod manifest.
+type AgentSpec struct {
+	// Image is the connect-agent container image.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// LogLevel is the log level of the connect-agent.
+	// +kubebuilder:validation:Enum=error;warn;info;debug;trace
+	// +optional
+	LogLevel string `json:"logLevel,omitempty"`
+
+	// Proxy defines the proxy settings for the connect-agent.
+	// When set, it replaces the default proxy settings as a whole.
+	// +optional
+	Proxy *AgentProxySpec `json:"proxy,omitempty"`
+
+	// TLSMode defines how the connect-agent verifies the gateway certificate.
+	// +kubebuilder:validation:Enum=strict;system-store
+	// +optional
+	TLSMode string `json:"tlsMode,omitempty"`
+
+	// AuthMode defines how the connect-agent authenticates with the gateway.
+	// +kubebuilder:validation:Enum=token;jwt
+	// +optional
+	AuthMode string `json:"authMode,omitempty"`
+
+	// Resources defines the compute resources of the connect-agent container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Tolerations defines the tolerations of the connect-agent Pod.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// ClientCertificate defines the client certificate presented by the connect-agent to a gateway that
+	// requires one.
+	// +optional
+	ClientCertificate *AgentClientCertificateSpec `json:"clientCertificate,omitempty"`
+}
+
+// AgentClientCertificateSpec defines the client certificate of the connect-agent. The connect-agent runs as a
+// static Pod, which can't mount Secrets, so the certificate and key are read from files on the edge node.
+type AgentClientCertificateSpec struct {
+	// CertPath is the path of the PEM-encoded client certificate on the edge node.
+	// +kubebuilder:validation:MinLength=1
+	CertPath string `json:"certPath"`
+
+	// KeyPath is the path of the PEM-encoded key of the client certificate on the edge node.
+	// +kubebuilder:validation:MinLength=1
+	KeyPath string `json:"keyPath"`
+}
+
+// AgentProxySpec defines the proxy settings for the connect-agent.
+type AgentProxySpec struct {
+	// HTTPProxy is the value of the HTTP_PROXY environment variable.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the value of the HTTPS_PROXY environment variable.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is the value of the NO_PROXY environment variable.
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// ClusterService is an in-cluster HTTP(S) service that can be reached through the connection gateway.
+type ClusterService struct {
+	// Namespace is the namespace of the Service.
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the Service.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Port is the port of the Service.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Scheme is the scheme of the Service. The certificate of an https Service is verified against the
+	// certificate authority of the cluster kubeconfig.
+	// +kubebuilder:validation:Enum=http;https
+	// +kubebuilder:default=http
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+
+	// InsecureSkipTLSVerify skips the verification of the certificate of an https Service, e.g. for a Service
+	// with a self-signed certificate.
+	// +optional
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
 }
 
 // ClusterConnectStatus defines the observed state of ClusterConnect.
@@ -80,22 +229,55 @@ type ClusterConnectStatus struct {
 	Ready bool `json:"ready,omitempty"`
 
 	// ControlPlaneEndpoint provides the URL for accessing the kubeapi-server through the connection gateway.
+	// Cluster API doesn't allow a sub-path in the endpoint, so use GatewayEndpoint to access the kubeapi-server.
 	// +optional
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint,omitempty"`
 
+	// GatewayEndpoint provides the URLs for accessing the kubeapi-server through the connection gateway.
+	// +optional
+	GatewayEndpoint *GatewayEndpoint `json:"gatewayEndpoint,omitempty"`
+
 	// AgentManifest is the connect-agent Pod manifest.
 	// +optional
 	AgentManifest string `json:"agentManifest,omitempty"`
 
+	// AgentTokenExpiresAt is the time the agent token expires at. The token is rotated ahead of this time.
+	// It is not set if the token never expires.
+	// +optional
+	AgentTokenExpiresAt *metav1.Time `json:"agentTokenExpiresAt,omitempty"`
+
+	// FirstReadyTimestamp is the time the ClusterConnect became ready for the first time.
+	// +optional
+	FirstReadyTimestamp *metav1.Time `json:"firstReadyTimestamp,omitempty"`
+
 	// ConnectionProbe defines the state of the connection with connect-agent.
 	ConnectionProbe ConnectionProbeState `json:"connectionProbe,omitempty"`
 
+	// Session describes the latest connect-agent session as recorded by the connection gateway.
+	// +optional
+	Session *AgentSession `json:"session,omitempty"`
+
 	// Conditions defines current connection state of the cluster.
 	// Known condition types are TBD.
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// GatewayEndpoint describes how to access the kubeapi-server through the connection gateway.
+type GatewayEndpoint struct {
+	// InternalURL is the URL of the kubeapi-server from within the management cluster.
+	// +optional
+	InternalURL string `json:"internalURL,omitempty"`
+
+	// ExternalURL is the URL of the kubeapi-server from outside the management cluster.
+	// +optional
+	ExternalURL string `json:"externalURL,omitempty"`
+
+	// CABundle is the PEM-encoded CA bundle to verify the certificate served at ExternalURL.
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+}
+
 type ConnectionProbeState struct {
 	// LastProbeTimestamp is the time when the health probe was executed last.
 	LastProbeTimestamp metav1.Time `json:"lastProbeTimestamp,omitempty"`
@@ -104,10 +286,48 @@ type ConnectionProbeState struct {
 	LastProbeSuccessTimestamp metav1.Time `json:"lastProbeSuccessTimestamp,omitempty"`
 }
 
+// AgentSession describes a connect-agent session held by a connection gateway replica.
+type AgentSession struct {
+	// Connected reports whether the connect-agent currently holds a session.
+	Connected bool `json:"connected"`
+
+	// RemoteAddress is the address the connect-agent connected from.
+	// +optional
+	RemoteAddress string `json:"remoteAddress,omitempty"`
+
+	// ConnectedAt is the time when the latest session was established.
+	// +optional
+	ConnectedAt *metav1.Time `json:"connectedAt,omitempty"`
+
+	// DisconnectedAt is the time when the latest session was closed.
+	// +optional
+	DisconnectedAt *metav1.Time `json:"disconnectedAt,omitempty"`
+
+	// GatewayReplica is the name of the connection gateway replica that holds the session.
+	// +optional
+	GatewayReplica string `json:"gatewayReplica,omitempty"`
+
+	// ReconnectCount is the number of sessions established after the first one.
+	// +optional
+	ReconnectCount int32 `json:"reconnectCount,omitempty"`
+
+	// AgentVersion is the version reported by the connect-agent.
+	// +optional
+	AgentVersion string `json:"agentVersion,omitempty"`
+
+	// LastDisconnectReason describes why the latest session was closed.
+	// +optional
+	LastDisconnectReason string `json:"lastDisconnectReason,omitempty"`
+}
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=clusterconnects,shortName=ccon,scope=Cluster
 // +kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+// +kubebuilder:printcolumn:name="Suspended",type="boolean",JSONPath=".spec.suspended",priority=1
+// +kubebuilder:printcolumn:name="Connected",type="boolean",JSONPath=".status.session.connected"
+// +kubebuilder:printcolumn:name="Gateway",type="string",JSONPath=".status.session.gatewayReplica",priority=1
+// +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".status.gatewayEndpoint.externalURL",priority=1
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Age of this resource"
 
 // ClusterConnect is the Schema for the clusterconnects API.
@@ -13,6 +13,96 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentClientCertificateSpec) DeepCopyInto(out *AgentClientCertificateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentClientCertificateSpec.
+func (in *AgentClientCertificateSpec) DeepCopy() *AgentClientCertificateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentClientCertificateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentProxySpec) DeepCopyInto(out *AgentProxySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentProxySpec.
+func (in *AgentProxySpec) DeepCopy() *AgentProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentSession) DeepCopyInto(out *AgentSession) {
+	*out = *in
+	if in.ConnectedAt != nil {
+		in, out := &in.ConnectedAt, &out.ConnectedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.DisconnectedAt != nil {
+		in, out := &in.DisconnectedAt, &out.DisconnectedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentSession.
+func (in *AgentSession) DeepCopy() *AgentSession {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentSession)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
+	*out = *in
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(AgentProxySpec)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(AgentClientCertificateSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentSpec.
+func (in *AgentSpec) DeepCopy() *AgentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterConnect) DeepCopyInto(out *ClusterConnect) {
 	*out = *in
@@ -90,6 +180,21 @@ func (in *ClusterConnectSpec) DeepCopyInto(out *ClusterConnectSpec) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.KubeconfigRef != nil {
+		in, out := &in.KubeconfigRef, &out.KubeconfigRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.Agent != nil {
+		in, out := &in.Agent, &out.Agent
+		*out = new(AgentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]ClusterService, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConnectSpec.
@@ -106,7 +211,25 @@ func (in *ClusterConnectSpec) DeepCopy() *ClusterConnectSpec {
 func (in *ClusterConnectStatus) DeepCopyInto(out *ClusterConnectStatus) {
 	*out = *in
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.GatewayEndpoint != nil {
+		in, out := &in.GatewayEndpoint, &out.GatewayEndpoint
+		*out = new(GatewayEndpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AgentTokenExpiresAt != nil {
+		in, out := &in.AgentTokenExpiresAt, &out.AgentTokenExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	if in.FirstReadyTimestamp != nil {
+		in, out := &in.FirstReadyTimestamp, &out.FirstReadyTimestamp
+		*out = (*in).DeepCopy()
+	}
 	in.ConnectionProbe.DeepCopyInto(&out.ConnectionProbe)
+	if in.Session != nil {
+		in, out := &in.Session, &out.Session
+		*out = new(AgentSession)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -126,6 +249,21 @@ func (in *ClusterConnectStatus) DeepCopy() *ClusterConnectStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterService) DeepCopyInto(out *ClusterService) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterService.
+func (in *ClusterService) DeepCopy() *ClusterService {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectionProbeState) DeepCopyInto(out *ConnectionProbeState) {
 	*out = *in
@@ -142,3 +280,23 @@ func (in *ConnectionProbeState) DeepCopy() *ConnectionProbeState {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayEndpoint) DeepCopyInto(out *GatewayEndpoint) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayEndpoint.
+func (in *GatewayEndpoint) DeepCopy() *GatewayEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -17,7 +17,7 @@ import (
 )
 
 func main() {
-	var gatewayUrl, tunnelId, logLevel, tokenPath, authToken, tunnelAuthMode string
+	var gatewayUrl, tunnelId, logLevel, tokenPath, authToken, tunnelAuthMode, clientCertFile, clientKeyFile string
 	var insecureSkipVerify bool
 	flag.StringVar(&gatewayUrl, "gateway-url", "", "The URL of the gateway")
 	// TODO: set this to false by default once CA mount is implemented
@@ -27,6 +27,8 @@ func main() {
 	flag.StringVar(&authToken, "auth-token", "", "The authentication token")
 	flag.StringVar(&logLevel, "log-level", "info", "Log levels: info, debug, trace")
 	flag.StringVar(&tokenPath, "token-path", "./access_token", "path to jwt token")
+	flag.StringVar(&clientCertFile, "client-cert-file", "", "path to the client certificate presented to the gateway")
+	flag.StringVar(&clientKeyFile, "client-key-file", "", "path to the key of the client certificate")
 	flag.Parse()
 
 	// Set log level for the tunnel data
@@ -50,6 +52,10 @@ func main() {
 		logger.Error("gateway-url and tunnel-id are required")
 		os.Exit(1)
 	}
+	if (clientCertFile == "") != (clientKeyFile == "") {
+		logger.Error("client-cert-file and client-key-file must be set together")
+		os.Exit(1)
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer func() {
@@ -64,6 +70,8 @@ func main() {
 		TokenPath:          tokenPath,
 		TunnelAuthMode:     tunnelAuthMode,
 		AuthToken:          authToken,
+		ClientCertFile:     clientCertFile,
+		ClientKeyFile:      clientKeyFile,
 	}
 
 	agent.Run(ctx)
@@ -16,6 +16,7 @@ import (
 
 	goruntime "runtime"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -31,6 +32,7 @@ import (
 	clusterv1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/agentconfig"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/controller"
+	webhookv1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -54,11 +56,14 @@ func main() {
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
 	var enableLeaderElection bool
+	var enableWebhooks, webhookRequireProjectID bool
+	var autoConnectSelector, autoConnectAnnotation string
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
-	var connectionProbeTimeout time.Duration
+	var connectionProbeTimeout, connectionProbeGracePeriod time.Duration
+	var agentTokenTTL, agentTokenGracePeriod time.Duration
 	var profilerAddress string
 	var enableContentionProfiling bool
 	var concurrency int
@@ -75,6 +80,13 @@ func main() {
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
 	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
 	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "If set, the admission webhooks for ClusterConnect are served.")
+	flag.BoolVar(&webhookRequireProjectID, "webhook-require-project-id", false,
+		"If set, the admission webhook rejects ClusterConnect names that are not prefixed with a project UUID.")
+	flag.StringVar(&autoConnectSelector, "auto-connect-selector", "",
+		"Label selector of the CAPI Clusters a ClusterConnect is created for automatically, e.g. connect=true.")
+	flag.StringVar(&autoConnectAnnotation, "auto-connect-annotation", "",
+		"Annotation of the CAPI Clusters a ClusterConnect is created for automatically, when set to \"true\".")
 	flag.StringVar(&metricsCertPath, "metrics-cert-path", "",
 		"The directory that contains the metrics server certificate.")
 	flag.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
@@ -82,6 +94,12 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.DurationVar(&connectionProbeTimeout, "connection-probe-timeout", 5*time.Minute, "The timeout duration for connection probes.")
+	flag.DurationVar(&connectionProbeGracePeriod, "connection-probe-grace-period", 30*time.Minute,
+		"Period after the creation of a ClusterConnect a connection probe that never succeeded is not reported as failed.")
+	flag.DurationVar(&agentTokenTTL, "agent-token-ttl", 0,
+		"Time-to-live of the agent tokens, which are rotated ahead of expiration. The tokens never expire if set to 0.")
+	flag.DurationVar(&agentTokenGracePeriod, "agent-token-grace-period", 24*time.Hour,
+		"Period the previous agent token is still accepted after a rotation.")
 	flag.StringVar(&profilerAddress, "profiler-address", "", "Bind address to expose the pprof profiler (e.g. localhost:6060)")
 	flag.BoolVar(&enableContentionProfiling, "contention-profiling", false, "Enable block profiling")
 	flag.IntVar(&concurrency, "concurrency", 1, "Maximum number of concurrent workers processing ClusterConnect resources")
@@ -105,11 +123,16 @@ func main() {
 		"webhookCertPath", webhookCertPath,
 		"webhookCertName", webhookCertName,
 		"webhookCertKey", webhookCertKey,
+		"enableWebhooks", enableWebhooks,
+		"webhookRequireProjectID", webhookRequireProjectID,
+		"autoConnectSelector", autoConnectSelector,
+		"autoConnectAnnotation", autoConnectAnnotation,
 		"metricsCertPath", metricsCertPath,
 		"metricsCertName", metricsCertName,
 		"metricsCertKey", metricsCertKey,
 		"enableHTTP2", enableHTTP2,
 		"connectionProbeTimeout", connectionProbeTimeout,
+		"connectionProbeGracePeriod", connectionProbeGracePeriod,
 		"profilerAddress", profilerAddress,
 		"enableContentionProfiling", enableContentionProfiling,
 		"concurrency", concurrency,
@@ -248,12 +271,37 @@ func main() {
 	ctx := ctrl.SetupSignalHandler()
 
 	if err = (&controller.ClusterConnectReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                     mgr.GetClient(),
+		Scheme:                     mgr.GetScheme(),
+		AgentTokenTTL:              agentTokenTTL,
+		AgentTokenGracePeriod:      agentTokenGracePeriod,
+		ConnectionProbeGracePeriod: connectionProbeGracePeriod,
 	}).SetupWithManager(ctx, mgr, connectionProbeTimeout, concurrency); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterConnect")
 		os.Exit(1)
 	}
+	if autoConnectSelector != "" || autoConnectAnnotation != "" {
+		clusterReconciler := &controller.ClusterReconciler{
+			Client:     mgr.GetClient(),
+			Annotation: autoConnectAnnotation,
+		}
+		if autoConnectSelector != "" {
+			if clusterReconciler.Selector, err = labels.Parse(autoConnectSelector); err != nil {
+				setupLog.Error(err, "invalid auto-connect selector", "selector", autoConnectSelector)
+				os.Exit(1)
+			}
+		}
+		if err = clusterReconciler.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Cluster")
+			os.Exit(1)
+		}
+	}
+	if enableWebhooks {
+		if err = webhookv1alpha1.SetupClusterConnectWebhookWithManager(mgr, webhookRequireProjectID); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterConnect")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
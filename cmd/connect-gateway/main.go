@@ -10,13 +10,18 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/atomix/dazl"
 	_ "github.com/atomix/dazl/zap"
 	"github.com/sirupsen/logrus"
 
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/audit"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/auth"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/middleware"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/server"
 	orchlibraryauth "github.com/open-edge-platform/orch-library/go/pkg/auth"
 )
@@ -26,32 +31,67 @@ var (
 )
 
 func main() {
-	var gatewayAddress, logLevel, opaAddress, oidcIssuerURL, externalHost, tunnelAuthMode string
-	var gatewayPort, opaPort int
+	var gatewayAddress, logLevel, opaAddress, oidcIssuerURL, externalHost, tunnelAuthMode, replicaName, adminTokenFile string
+	var gatewayPort, opaPort, metricsMaxTunnels, clientCacheSize int
 	var enableAuth, enableMetrics, oidcInsecureSkipVerify, tlsInsecureSkipVerify bool
-	var connectionProbeInterval time.Duration
+	var connectionProbeInterval, shutdownTimeout, clientCacheIdleTimeout, requestTimeout time.Duration
+	var tlsAddress, tlsCertFile, tlsKeyFile, agentClientCAFile string
+	var tunnelRateLimit, userRateLimit middleware.RateLimit
+	var auditLevel, auditLogPath, auditWebhookURL string
+	var auditLogMaxSize, auditLogMaxBackup int
+	var impersonate bool
+	var impersonationGroupsClaims string
+	var impersonation middleware.Impersonation
 	flag.StringVar(&gatewayAddress, "address", "0.0.0.0", "Address to listen on for edge connection gateway")
 	flag.IntVar(&gatewayPort, "port", 8080, "Port to listen on for edge connection gateway")
 	flag.BoolVar(&enableAuth, "enable-auth", false, "Enable OIDC authentication")
 	flag.BoolVar(&enableMetrics, "enable-metrics", false, "Enable metrics")
+	flag.IntVar(&metricsMaxTunnels, "metrics-max-tunnels", 100, "Maximum number of tunnels labeled with their tunnel ID in the metrics, the requests of the other tunnels are labeled 'other'. Unlimited if 0")
 	flag.StringVar(&logLevel, "log-level", "info", "Log levels: info, debug, trace, warn")
 	flag.StringVar(&oidcIssuerURL, "oidc-issuer-url", "", "OIDC Issuer URL")
 	flag.BoolVar(&oidcInsecureSkipVerify, "oidc-insecure-skip-verify", false, "OIDC Insecure Skip Verify")
 	flag.BoolVar(&tlsInsecureSkipVerify, "tls-insecure-skip-verify", false, "Skip TLS certificate verification for client connections")
+	flag.StringVar(&tlsAddress, "tls-address", "0.0.0.0:8443", "Address to listen on with TLS, if a TLS certificate is set")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "Path to the TLS certificate. The certificate is reloaded when it changes. TLS is disabled if not set")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", "", "Path to the TLS private key")
+	flag.StringVar(&agentClientCAFile, "agent-client-ca-file", "", "Path to the CA that signs the agent client certificates. Agents must connect with TLS and present a client certificate if set")
 	flag.StringVar(&externalHost, "external-host", "", "External host for the gateway")
 
 	flag.StringVar(&opaAddress, "opa-address", "http://localhost", "Address to opa")
 	flag.IntVar(&opaPort, "opa-port", 8181, "Port to opa")
 	flag.StringVar(&tunnelAuthMode, "tunnel-auth-mode", "token", "Specify the authentication mode for tunnel connections: 'token' or 'jwt'")
 	flag.DurationVar(&connectionProbeInterval, "connection-probe-interval", 1*time.Minute, "Interval for connection probe checks")
+	flag.IntVar(&clientCacheSize, "client-cache-size", 1000, "Maximum number of cached Kubernetes API clients of the tunnels. Unlimited if 0")
+	flag.DurationVar(&clientCacheIdleTimeout, "client-cache-idle-timeout", 30*time.Minute, "Time after which an unused cached Kubernetes API client of a tunnel is evicted. Never if 0")
+	flag.DurationVar(&requestTimeout, "request-timeout", 60*time.Second, "Maximum and default deadline of the proxied Kubernetes API requests, except the watches and streams. Only the timeouts requested by the clients apply if 0")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 20*time.Second, "Deadline for the in-flight Kubernetes API requests to complete on shutdown, before the agent sessions are closed")
+	flag.Float64Var(&tunnelRateLimit.QPS, "tunnel-qps", 0, "Maximum rate of Kubernetes API requests per second to a tunnel. Unlimited if 0")
+	flag.IntVar(&tunnelRateLimit.Burst, "tunnel-burst", 0, "Maximum burst of Kubernetes API requests to a tunnel")
+	flag.IntVar(&tunnelRateLimit.MaxInflight, "tunnel-max-inflight", 0, "Maximum number of concurrent Kubernetes API requests to a tunnel, excluding watches and streams. Unlimited if 0")
+	flag.Float64Var(&userRateLimit.QPS, "user-qps", 0, "Maximum rate of Kubernetes API requests per second of a user (JWT subject). Unlimited if 0")
+	flag.IntVar(&userRateLimit.Burst, "user-burst", 0, "Maximum burst of Kubernetes API requests of a user")
+	flag.IntVar(&userRateLimit.MaxInflight, "user-max-inflight", 0, "Maximum number of concurrent Kubernetes API requests of a user, excluding watches and streams. Unlimited if 0")
+	flag.BoolVar(&impersonate, "impersonate", false, "Impersonate the users authenticated with a JWT on the edge clusters, instead of proxying their requests as the cluster admin. Requires --enable-auth")
+	flag.StringVar(&impersonation.UsernameClaim, "impersonation-username-claim", "preferred_username", "JWT claim of the impersonated username. The subject is used if the claim is missing")
+	flag.StringVar(&impersonationGroupsClaims, "impersonation-groups-claims", "groups", "Comma separated JWT claims of the impersonated groups. Nested claims are separated by dots, e.g. realm_access.roles")
+	flag.StringVar(&impersonation.UsernamePrefix, "impersonation-username-prefix", "", "Prefix of the impersonated usernames")
+	flag.StringVar(&impersonation.GroupsPrefix, "impersonation-groups-prefix", "", "Prefix of the impersonated groups")
+	flag.StringVar(&auditLevel, "audit-level", "None", "Audit level of the Kubernetes API requests: None, Metadata, Request or RequestResponse")
+	flag.StringVar(&auditLogPath, "audit-log-path", "", "Path to the audit log file, or '-' for the standard output. The audit log is disabled if not set")
+	flag.IntVar(&auditLogMaxSize, "audit-log-maxsize", 100, "Maximum size in megabytes of the audit log file before it is rotated")
+	flag.IntVar(&auditLogMaxBackup, "audit-log-maxbackup", 10, "Maximum number of rotated audit log files to keep")
+	flag.StringVar(&auditWebhookURL, "audit-webhook-url", "", "URL of a webhook that receives the audit events in batches. The audit webhook is disabled if not set")
+	flag.StringVar(&replicaName, "replica-name", os.Getenv("POD_NAME"), "Name of this gateway replica recorded in the agent session status (defaults to the hostname)")
+	flag.StringVar(&adminTokenFile, "admin-token-file", "", "Path to the file with the bearer token of the admin API. The admin API is disabled if not set")
 	flag.Parse()
 
 	setLogLevel(logLevel)
+	metrics.SetMaxTunnelLabels(metricsMaxTunnels)
 	log.Infof("Agent authentication mode for tunnel connections %s", tunnelAuthMode)
 	var tunnelAuth func(req *http.Request) (clientKey string, authed bool, err error)
 	switch tunnelAuthMode {
 	case "token":
-		tokenManager, err := auth.NewTokenManager()
+		tokenManager, err := auth.NewTokenManager(auth.WithCache(context.Background()))
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -62,10 +102,38 @@ func main() {
 		tunnelAuth = jwtAuth.Authorizer
 	}
 
+	var adminToken string
+	if adminTokenFile != "" {
+		token, err := os.ReadFile(adminTokenFile)
+		if err != nil {
+			log.Fatalf("Failed to read admin token: %v", err)
+		}
+		adminToken = strings.TrimSpace(string(token))
+		log.Info("Admin API enabled")
+	}
+
+	auditor, err := newAuditor(auditLevel, auditLogPath, auditLogMaxSize, auditLogMaxBackup, auditWebhookURL)
+	if err != nil {
+		log.Fatalf("Failed to create auditor: %v", err)
+	}
+	defer func() {
+		if err := auditor.Close(); err != nil {
+			log.Warnf("Failed to close auditor: %v", err)
+		}
+	}()
+
+	for _, claim := range strings.Split(impersonationGroupsClaims, ",") {
+		if claim = strings.TrimSpace(claim); claim != "" {
+			impersonation.GroupsClaims = append(impersonation.GroupsClaims, claim)
+		}
+	}
+	if impersonate {
+		log.Info("Impersonating the authenticated users on the edge clusters")
+	}
+
 	listenAddr := fmt.Sprintf("%s:%d", gatewayAddress, gatewayPort)
-	// TODO: make the # of hours configurable via helm chart. Will use minutes so we can trigger it quick if needed
-	// hours seems too much
-	clientCleanupTicker := time.NewTicker(480 * time.Minute)
+	// The cached clients of the idle and disconnected tunnels are evicted every minute.
+	clientCleanupTicker := time.NewTicker(time.Minute)
 	defer clientCleanupTicker.Stop()
 
 	connectionProbeTicker := time.NewTicker(connectionProbeInterval)
@@ -80,36 +148,63 @@ func main() {
 		server.WithOIDCInsecureSkipVerify(oidcInsecureSkipVerify),
 		server.WithTLSInsecureSkipVerify(tlsInsecureSkipVerify),
 		server.WithCleanupTicker(clientCleanupTicker),
+		server.WithClientCache(clientCacheSize, clientCacheIdleTimeout),
+		server.WithRequestTimeout(requestTimeout),
 		server.WithConnectionProbeTicker(connectionProbeTicker),
+		server.WithReplicaName(replicaName),
+		server.WithAdminToken(adminToken),
+		server.WithShutdownTimeout(shutdownTimeout),
+		server.WithTLS(tlsAddress, tlsCertFile, tlsKeyFile),
+		server.WithAgentClientCA(agentClientCAFile),
+		server.WithRateLimits(tunnelRateLimit, userRateLimit),
+		server.WithAuditor(auditor),
+		server.WithImpersonation(impersonate, impersonation),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create gateway server: %v", err)
 		os.Exit(1)
 	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-
-	// Create an error channel
-	errChan := make(chan error, 1)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Shut down gracefully on SIGTERM, which is sent by Kubernetes when the Pod is terminated.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	log.Infof("Starting edge connection gateway server on %s", listenAddr)
 	log.Infof("Connection probe interval set to %s", connectionProbeInterval)
-	go runServer(ctx, server, errChan)
-
-	// Wait for either an error or an OS signal
-	select {
-	case err := <-errChan:
-		// Handle the error
+	if err := server.Run(ctx); err != nil {
 		log.Errorf("Error encountered: %s", err)
-	case sig := <-c:
-		// Handle the signal
-		log.Infof("Got %s signal. Aborting...", sig)
 	}
 }
 
+func newAuditor(level, logPath string, maxSize, maxBackup int, webhookURL string) (*audit.Auditor, error) {
+	auditLevel, err := audit.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	var sinks []audit.Sink
+	switch logPath {
+	case "":
+	case "-":
+		sinks = append(sinks, audit.NewLogSink(os.Stdout))
+	default:
+		sink, err := audit.NewFileSink(logPath, maxSize, maxBackup)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if webhookURL != "" {
+		sinks = append(sinks, audit.NewWebhookSink(webhookURL, &http.Client{Timeout: 10 * time.Second}))
+	}
+
+	auditor := audit.New(auditLevel, sinks...)
+	if auditor.Enabled() {
+		log.Infof("Auditing Kubernetes API requests at level %s", auditLevel)
+	}
+	return auditor, nil
+}
+
 func setLogLevel(logLevel string) {
 	var level dazl.Level
 	switch logLevel {
@@ -126,16 +221,3 @@ func setLogLevel(logLevel string) {
 	}
 	dazl.GetRootLogger().SetLevel(level)
 }
-
-func runServer(ctx context.Context, server *server.Server, errChan chan error) {
-	select {
-	case <-ctx.Done():
-		// Handle the context being canceled
-		log.Info("Context canceled")
-	default:
-		// Catch any error from Run and send it to the error channel
-		if err := server.Run(); err != nil {
-			errChan <- err
-		}
-	}
-}
@@ -85,3 +85,21 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Directory of the audit log file, which is mounted from a volume
+*/}}
+{{- define "cluster-connect-gateway.auditLogDir" -}}
+{{- $dir := dir .Values.gateway.audit.logPath }}
+{{- if or (not (isAbs $dir)) (eq $dir "/") }}
+{{- fail "gateway.audit.logPath must be \"-\" or an absolute path in a directory other than /" }}
+{{- end }}
+{{- $dir }}
+{{- end }}
+
+{{/*
+Name of the Secret holding the token of the admin API, either given or generated
+*/}}
+{{- define "cluster-connect-gateway.adminTokenSecretName" -}}
+{{- default (printf "%s-admin-token" (include "cluster-connect-gateway.fullname" .)) .Values.gateway.adminApi.existingSecret }}
+{{- end }}
@@ -5,6 +5,7 @@ package agent
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -27,19 +28,39 @@ type ConnectAgent struct {
 	TunnelId           string
 	TokenPath          string
 	TunnelAuthMode     string
+	// ClientCertFile and ClientKeyFile are the client certificate presented to the gateway, if it requires one.
+	ClientCertFile string
+	ClientKeyFile  string
 }
 
 const (
-	TunnelIdHeader = "X-Tunnel-Id"        // #nosec G101
-	TokenHeader    = "X-API-Tunnel-Token" // #nosec G101
+	TunnelIdHeader     = "X-Tunnel-Id"             // #nosec G101
+	TokenHeader        = "X-API-Tunnel-Token"      // #nosec G101
+	AgentVersionHeader = "X-Connect-Agent-Version" // #nosec G101
 )
 
+// Version is the connect-agent version reported to the gateway. It is set at build time.
+var Version = "dev"
+
 func (c *ConnectAgent) Run(ctx context.Context) {
 	// Use Go's built-in resolver to resolve DNS names.  We may want to revisit this.
 	resolver := &net.Resolver{
 		PreferGo: true,
 	}
 
+	tlsConfig := certutil.GetTLSConfigs(c.InsecureSkipVerify)
+	if c.ClientCertFile != "" {
+		// Load the certificate on each connection, so that a renewed certificate is used on reconnect.
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+			if err != nil {
+				zap.L().Error("Error loading client certificate", zap.Error(err))
+				return nil, err
+			}
+			return &cert, nil
+		}
+	}
+
 	// Create a new dialer with the resolver and the TLS configuration
 	dialer := &websocket.Dialer{
 		HandshakeTimeout: 10 * time.Minute,
@@ -48,10 +69,11 @@ func (c *ConnectAgent) Run(ctx context.Context) {
 			KeepAlive: 30 * time.Second,
 			Resolver:  resolver,
 		}).DialContext,
-		TLSClientConfig: certutil.GetTLSConfigs(c.InsecureSkipVerify),
+		TLSClientConfig: tlsConfig,
 	}
 	headers := http.Header{
-		TunnelIdHeader: {c.TunnelId},
+		TunnelIdHeader:     {c.TunnelId},
+		AgentVersionHeader: {Version},
 	}
 	switch c.TunnelAuthMode {
 	case "token":
@@ -9,6 +9,11 @@ import (
 	"html/template"
 	"net/url"
 	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 )
 
 const (
@@ -18,11 +23,17 @@ metadata:
   name: connect-agent
   namespace: kube-system
 spec:
+{{- if .Tolerations }}
+  tolerations:
+{{ .Tolerations }}
+{{- end }}
   containers:
   - name: connect-agent
     image: "{{.Image}}"
-{{- if ne .HttpProxy "" }}
+{{- if or .HttpProxy .HttpsProxy .NoProxy }}
     env:
+{{- end }}
+{{- if ne .HttpProxy "" }}
     - name: HTTP_PROXY
       value: {{.HttpProxy}}
 {{- end }}
@@ -43,6 +54,10 @@ spec:
     - "--log-level={{.LogLevel}}"
     - "--token-path={{.TokenPath}}"
     - "--tunnel-auth-mode={{.AgentAuthMode}}"
+{{- if .ClientCertPath }}
+    - "--client-cert-file=/etc/connect-agent/client-cert/tls.crt"
+    - "--client-key-file=/etc/connect-agent/client-cert/tls.key"
+{{- end }}
     securityContext:
 {{- if eq .AgentAuthMode "jwt" }}
       runAsUser: 501
@@ -56,10 +71,14 @@ spec:
       seccompProfile:
         type: RuntimeDefault
     resources:
+{{- if .Resources }}
+{{ .Resources }}
+{{- else }}
       limits: {}
       requests:
         cpu: 100m
         memory: 128Mi
+{{- end }}
     volumeMounts:
 {{- if eq .TLSMode "system-store" }}		
     - name: server-ca
@@ -70,6 +89,14 @@ spec:
     - name: jwt-token
       mountPath: {{.TokenPath}}
       readOnly: true
+{{- end }}
+{{- if .ClientCertPath }}
+    - name: client-cert
+      mountPath: /etc/connect-agent/client-cert/tls.crt
+      readOnly: true
+    - name: client-key
+      mountPath: /etc/connect-agent/client-cert/tls.key
+      readOnly: true
 {{- end }}
   volumes:
 {{- if eq .TLSMode "system-store" }}	
@@ -83,6 +110,16 @@ spec:
     hostPath:
       path: {{.TokenPath}}
       type: File
+{{- end }}
+{{- if .ClientCertPath }}
+  - name: client-cert
+    hostPath:
+      path: {{.ClientCertPath}}
+      type: File
+  - name: client-key
+    hostPath:
+      path: {{.ClientKeyPath}}
+      type: File
 {{- end }}`
 )
 
@@ -105,6 +142,14 @@ type config struct {
 	TLSMode            string
 	TokenPath          string
 	AgentAuthMode      string
+	// ClientCertPath and ClientKeyPath are the paths on the edge node of the agent client certificate, if any.
+	ClientCertPath string
+	ClientKeyPath  string
+
+	// Resources and Tolerations hold pre-rendered and indented YAML blocks.
+	// They are rendered from typed API fields, so they are not escaped by the template.
+	Resources   template.HTML
+	Tolerations template.HTML
 }
 
 // InitAgentConfig initializes the agent configuration by reading environment variables.
@@ -153,23 +198,98 @@ func InitAgentConfig() error {
 	agentconfig.NoProxy = os.Getenv("NO_PROXY")
 	agentconfig.TLSMode = getEnv("TLS_MODE", "strict")
 	agentconfig.AgentAuthMode = getEnv("AGENT_AUTH_MODE", "token")
+	agentconfig.ClientCertPath = os.Getenv("AGENT_CLIENT_CERT_PATH")
+	agentconfig.ClientKeyPath = os.Getenv("AGENT_CLIENT_KEY_PATH")
+	if (agentconfig.ClientCertPath == "") != (agentconfig.ClientKeyPath == "") {
+		return fmt.Errorf("AGENT_CLIENT_CERT_PATH and AGENT_CLIENT_KEY_PATH must be set together")
+	}
 
 	return nil
 }
 
 // GenerateAgentConfig generates the connect-agent pod manifest in YAML for a given tunnel ID and token.
+// Fields set in the optional per-cluster spec override the defaults read by InitAgentConfig.
 // It returns the generated manifest as a string and any error encountered during template execution.
-func GenerateAgentConfig(tunnelId, token string) (string, error) {
+func GenerateAgentConfig(tunnelId, token string, spec *v1alpha1.AgentSpec) (string, error) {
 	// Do not modify the original config
 	config := agentconfig
 	config.TunnelID = tunnelId
 	config.Token = token
 
+	if err := config.merge(spec); err != nil {
+		return "", err
+	}
+
 	buf := new(bytes.Buffer)
 	err := agentTemplate.Execute(buf, &config)
 	return buf.String(), err
 }
 
+// merge overrides the config with the fields set in the per-cluster agent spec.
+func (c *config) merge(spec *v1alpha1.AgentSpec) error {
+	if spec == nil {
+		return nil
+	}
+
+	if spec.Image != "" {
+		c.Image = spec.Image
+	}
+	if spec.LogLevel != "" {
+		c.LogLevel = spec.LogLevel
+	}
+	if spec.TLSMode != "" {
+		c.TLSMode = spec.TLSMode
+	}
+	if spec.AuthMode != "" {
+		c.AgentAuthMode = spec.AuthMode
+	}
+	if spec.ClientCertificate != nil {
+		c.ClientCertPath = spec.ClientCertificate.CertPath
+		c.ClientKeyPath = spec.ClientCertificate.KeyPath
+	}
+
+	// Proxy settings are replaced as a whole so that a cluster can opt out of the default proxy.
+	if spec.Proxy != nil {
+		c.HttpProxy = spec.Proxy.HTTPProxy
+		c.HttpsProxy = spec.Proxy.HTTPSProxy
+		c.NoProxy = spec.Proxy.NoProxy
+	}
+
+	if spec.Resources != nil {
+		resources, err := toIndentedYAML(spec.Resources, 6)
+		if err != nil {
+			return fmt.Errorf("failed to render agent resources: %v", err)
+		}
+		c.Resources = resources
+	}
+
+	if len(spec.Tolerations) > 0 {
+		tolerations, err := toIndentedYAML(spec.Tolerations, 2)
+		if err != nil {
+			return fmt.Errorf("failed to render agent tolerations: %v", err)
+		}
+		c.Tolerations = tolerations
+	}
+
+	return nil
+}
+
+// toIndentedYAML marshals a given object to YAML and indents every line with the given number of spaces.
+func toIndentedYAML(obj interface{}, indent int) (template.HTML, error) {
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	prefix := strings.Repeat(" ", indent)
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+
+	return template.HTML(strings.Join(lines, "\n")), nil // #nosec G203 -- rendered from typed API fields
+}
+
 func getEnv(key string, defaultValue string) string {
 	value := os.Getenv(key)
 	if value == "" {
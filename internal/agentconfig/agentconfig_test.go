@@ -6,6 +6,13 @@ package agentconfig
 import (
 	"os"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 )
 
 //nolint:errcheck
@@ -72,7 +79,7 @@ spec:
 
 	tunnelId := "test-tunnel-id"
 	token := "test-token"
-	configStr, err := GenerateAgentConfig(tunnelId, token)
+	configStr, err := GenerateAgentConfig(tunnelId, token, nil)
 
 	if err != nil {
 		t.Fatalf("GenerateAgentConfig() error = %v", err)
@@ -82,3 +89,71 @@ spec:
 		t.Errorf("expected \n%s\ngot \n%s", expected, configStr)
 	}
 }
+
+//nolint:errcheck
+func TestGenerateAgentConfigWithOverrides(t *testing.T) {
+	os.Setenv("AGENT_IMAGE", "connect-gateway:latest")
+	os.Setenv("GATEWAY_EXTERNAL_URL", "https://connect-gateway.kind.internal")
+	os.Setenv("AGENT_JWT_TOKEN_PATH", "/testpath")
+
+	originalHTTPProxy := os.Getenv("HTTP_PROXY")
+	os.Setenv("HTTP_PROXY", "http://default-proxy:3128")
+	defer os.Setenv("HTTP_PROXY", originalHTTPProxy)
+
+	err := InitAgentConfig()
+	if err != nil {
+		t.Fatalf("InitAgentConfig() error = %v", err)
+	}
+
+	spec := &v1alpha1.AgentSpec{
+		Image:    "connect-agent:pinned",
+		LogLevel: "debug",
+		Proxy: &v1alpha1.AgentProxySpec{
+			HTTPSProxy: "http://site-proxy:3128",
+			NoProxy:    "10.0.0.0/8",
+		},
+		Resources: &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+		Tolerations: []corev1.Toleration{
+			{
+				Key:      "node-role.kubernetes.io/control-plane",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			},
+		},
+		ClientCertificate: &v1alpha1.AgentClientCertificateSpec{
+			CertPath: "/etc/intel_edge_node/certs/connect-agent.crt",
+			KeyPath:  "/etc/intel_edge_node/certs/connect-agent.key",
+		},
+	}
+
+	configStr, err := GenerateAgentConfig("test-tunnel-id", "test-token", spec)
+	if err != nil {
+		t.Fatalf("GenerateAgentConfig() error = %v", err)
+	}
+
+	pod := &corev1.Pod{}
+	if err := yaml.UnmarshalStrict([]byte(configStr), pod); err != nil {
+		t.Fatalf("generated manifest is not a valid Pod: %v\n%s", err, configStr)
+	}
+
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, "connect-agent:pinned", container.Image)
+	assert.Contains(t, container.Args, "--log-level=debug")
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "HTTPS_PROXY", Value: "http://site-proxy:3128"},
+		{Name: "NO_PROXY", Value: "10.0.0.0/8"},
+	}, container.Env)
+	assert.True(t, container.Resources.Limits.Memory().Equal(resource.MustParse("256Mi")))
+	assert.Empty(t, container.Resources.Requests)
+	assert.Equal(t, spec.Tolerations, pod.Spec.Tolerations)
+	assert.Contains(t, container.Args, "--client-cert-file=/etc/connect-agent/client-cert/tls.crt")
+	assert.Contains(t, container.Args, "--client-key-file=/etc/connect-agent/client-cert/tls.key")
+	hostPathFile := corev1.HostPathFile
+	assert.Contains(t, pod.Spec.Volumes, corev1.Volume{Name: "client-key", VolumeSource: corev1.VolumeSource{
+		HostPath: &corev1.HostPathVolumeSource{Path: "/etc/intel_edge_node/certs/connect-agent.key", Type: &hostPathFile},
+	}})
+}
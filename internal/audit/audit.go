@@ -0,0 +1,291 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+// Package audit records the Kubernetes API requests proxied by the gateway as Kubernetes audit events.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/atomix/dazl"
+	_ "github.com/atomix/dazl/zap"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	authnv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/middleware"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/proxyutil"
+)
+
+var log = dazl.GetPackageLogger()
+
+// Annotations of the audit events with the gateway specific details.
+const (
+	TunnelIDAnnotation  = "cluster.edge-orchestrator.intel.com/tunnel-id"
+	ProjectIDAnnotation = "cluster.edge-orchestrator.intel.com/project-id"
+	UpgradeAnnotation   = "cluster.edge-orchestrator.intel.com/upgrade"
+	LatencyAnnotation   = "cluster.edge-orchestrator.intel.com/latency"
+)
+
+// metadataOnlyResources are the core resources whose requests are recorded at most at the Metadata level, so that
+// the audit events don't leak their data.
+var metadataOnlyResources = sets.New("secrets", "configmaps")
+
+// maxObjectSize is the maximum size of the request and response bodies recorded in an audit event.
+// Larger bodies are not recorded.
+const maxObjectSize = 64 * 1024
+
+var levels = []auditv1.Level{auditv1.LevelNone, auditv1.LevelMetadata, auditv1.LevelRequest, auditv1.LevelRequestResponse}
+
+// ParseLevel parses a Kubernetes audit level.
+func ParseLevel(level string) (auditv1.Level, error) {
+	for _, l := range levels {
+		if strings.EqualFold(level, string(l)) {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("invalid audit level %q, expected one of %v", level, levels)
+}
+
+func atLeast(level, min auditv1.Level) bool {
+	index := func(l auditv1.Level) int {
+		for i := range levels {
+			if levels[i] == l {
+				return i
+			}
+		}
+		return 0
+	}
+	return index(level) >= index(min)
+}
+
+// Sink stores the audit events.
+type Sink interface {
+	// ProcessEvent stores a given event. It must not block on slow backends.
+	ProcessEvent(event *auditv1.Event)
+	// Close flushes the pending events and releases the resources of the sink.
+	Close() error
+}
+
+// Auditor records the requests to the /kubernetes and /services endpoints at a given audit level.
+type Auditor struct {
+	level auditv1.Level
+	sinks []Sink
+}
+
+// New creates an Auditor that records the requests at a given level into given sinks.
+func New(level auditv1.Level, sinks ...Sink) *Auditor {
+	return &Auditor{
+		level: level,
+		sinks: sinks,
+	}
+}
+
+// Enabled reports whether the requests are recorded.
+func (a *Auditor) Enabled() bool {
+	return a.level != auditv1.LevelNone && len(a.sinks) > 0
+}
+
+// Close closes the sinks, flushing the pending events.
+func (a *Auditor) Close() error {
+	var errs []error
+	for _, sink := range a.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Middleware records the requests to the /kubernetes/{tunnel_id}/{kubernetes_uri} routes, and the requests to the
+// /services/{tunnel_id}/{namespace}/{service}:{port}/{service_uri} routes as service proxy requests. It must come
+// before the AuthMiddleware, so that the requests it rejects are recorded as well, with the JWT subject of the
+// requests whose token it validated.
+func (a *Auditor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		event := a.newEvent(req, start)
+		longRunning := req.Header.Get("Upgrade") != "" || event.Verb == "watch"
+
+		ctx, claims := middleware.ContextWithClaimsRecorder(req.Context())
+		req = req.WithContext(ctx)
+
+		var requestBody *limitedBuffer
+		if atLeast(event.Level, auditv1.LevelRequest) && !longRunning && req.Body != nil && req.Body != http.NoBody {
+			requestBody = &limitedBuffer{limit: maxObjectSize}
+			req.Body = &teeReadCloser{ReadCloser: req.Body, w: requestBody}
+		}
+		var responseBody *limitedBuffer
+		w := &proxyutil.ResponseRecorder{ResponseWriter: rw}
+		if atLeast(event.Level, auditv1.LevelRequestResponse) && !longRunning {
+			responseBody = &limitedBuffer{limit: maxObjectSize}
+			w.Body = responseBody
+		}
+
+		// The start of the long-running responses is recorded as well, as they may last for hours.
+		if longRunning {
+			w.OnResponseStarted = func(code int) {
+				started := event.DeepCopy()
+				started.Stage = auditv1.StageResponseStarted
+				started.StageTimestamp = metav1.NewMicroTime(time.Now())
+				started.User = userInfo(claims)
+				started.ResponseStatus = &metav1.Status{Code: int32(code)} // #nosec G115 -- HTTP status code
+				a.process(started)
+			}
+		}
+
+		defer func() {
+			now := time.Now()
+			event.Stage = auditv1.StageResponseComplete
+			event.StageTimestamp = metav1.NewMicroTime(now)
+			event.User = userInfo(claims)
+			event.Annotations[LatencyAnnotation] = now.Sub(start).String()
+			event.ResponseStatus = &metav1.Status{Code: int32(w.StatusCode())} // #nosec G115 -- HTTP status code
+			event.RequestObject = requestBody.object()
+			event.ResponseObject = responseBody.object()
+			a.process(event)
+		}()
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (a *Auditor) process(event *auditv1.Event) {
+	for _, sink := range a.sinks {
+		sink.ProcessEvent(event)
+	}
+}
+
+// newEvent creates the audit event of a given request, before it is served and authenticated.
+func (a *Auditor) newEvent(req *http.Request, start time.Time) *auditv1.Event {
+	vars := mux.Vars(req)
+	tunnelID := vars["tunnel_id"]
+
+	// Parse the request as it is received by the Kubernetes API server of the edge cluster.
+	kubeReq := proxyutil.KubernetesRequest(req, proxyutil.KubernetesURI(vars))
+
+	event := &auditv1.Event{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Event",
+			APIVersion: auditv1.SchemeGroupVersion.String(),
+		},
+		Level:                    a.level,
+		AuditID:                  types.UID(uuid.NewString()),
+		RequestURI:               kubeReq.URL.RequestURI(),
+		Verb:                     strings.ToLower(req.Method),
+		SourceIPs:                sourceIPs(req),
+		UserAgent:                req.UserAgent(),
+		RequestReceivedTimestamp: metav1.NewMicroTime(start),
+		Annotations: map[string]string{
+			TunnelIDAnnotation: tunnelID,
+		},
+	}
+	if projectID, err := middleware.ProjectIDFromTunnel(tunnelID); err == nil {
+		event.Annotations[ProjectIDAnnotation] = projectID
+	}
+
+	info, err := proxyutil.NewRequestInfo(kubeReq)
+	if err != nil {
+		log.Debugf("Failed to parse request %s: %v", kubeReq.URL.Path, err)
+		return event
+	}
+	event.Verb = info.Verb
+	if info.IsResourceRequest {
+		event.ObjectRef = &auditv1.ObjectReference{
+			Resource:    info.Resource,
+			Namespace:   info.Namespace,
+			Name:        info.Name,
+			APIGroup:    info.APIGroup,
+			APIVersion:  info.APIVersion,
+			Subresource: info.Subresource,
+		}
+		if info.APIGroup == "" && metadataOnlyResources.Has(info.Resource) && atLeast(event.Level, auditv1.LevelRequest) {
+			event.Level = auditv1.LevelMetadata
+		}
+	}
+	// The upgraded requests are exec, attach and port-forward streams.
+	if upgrade := req.Header.Get("Upgrade"); upgrade != "" {
+		event.Annotations[UpgradeAnnotation] = info.Subresource
+		if info.Subresource == "" {
+			event.Annotations[UpgradeAnnotation] = strings.ToLower(upgrade)
+		}
+	}
+	return event
+}
+
+// userInfo returns the user of a request whose token was validated by the AuthMiddleware. The requests from within
+// the cluster, and the requests without a valid token, are not authenticated and their user is empty.
+func userInfo(recorder *middleware.ClaimsRecorder) authnv1.UserInfo {
+	claims, ok := recorder.Claims()
+	if !ok {
+		return authnv1.UserInfo{}
+	}
+	user := authnv1.UserInfo{}
+	if subject, err := claims.GetSubject(); err == nil {
+		user.UID = subject
+		user.Username = subject
+	}
+	if mapClaims, ok := claims.(jwt.MapClaims); ok {
+		if username, ok := mapClaims["preferred_username"].(string); ok && username != "" {
+			user.Username = username
+		}
+	}
+	return user
+}
+
+func sourceIPs(req *http.Request) []string {
+	var ips []string
+	for _, ip := range utilnet.SourceIPs(req) {
+		ips = append(ips, ip.String())
+	}
+	return ips
+}
+
+// limitedBuffer buffers up to a given number of bytes, and remembers whether more were written.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.truncated || b.Len()+len(p) > b.limit {
+		b.truncated = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// object returns the buffered body as an audit event object, if it is a complete JSON document.
+func (b *limitedBuffer) object() *runtime.Unknown {
+	if b == nil || b.truncated || b.Len() == 0 || !json.Valid(b.Bytes()) {
+		return nil
+	}
+	return &runtime.Unknown{Raw: b.Bytes(), ContentType: runtime.ContentTypeJSON}
+}
+
+type teeReadCloser struct {
+	io.ReadCloser
+	w io.Writer
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = t.w.Write(p[:n])
+	}
+	return n, err
+}
@@ -0,0 +1,281 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/middleware"
+)
+
+const testTunnelID = "0c6f1c38-8bd6-4bc1-a1ac-e7d2ee43ea1f-edge-cluster"
+
+// memorySink keeps the events in memory.
+type memorySink struct {
+	mu     sync.Mutex
+	events []*auditv1.Event
+}
+
+func (s *memorySink) ProcessEvent(event *auditv1.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *memorySink) Close() error { return nil }
+
+// serve serves a request through the auditor on the /kubernetes and /services routes of the gateway, followed by
+// an authentication as a given JWT subject.
+func serve(auditor *Auditor, handler http.HandlerFunc, req *http.Request, subject string) {
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subject != "" {
+				claims := jwt.MapClaims{"sub": subject, "preferred_username": "alice"}
+				r = r.WithContext(middleware.ContextWithClaims(r.Context(), claims))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	router := mux.NewRouter()
+	k := router.PathPrefix("/kubernetes").Subrouter()
+	k.HandleFunc("/{tunnel_id}/{kubernetes_uri:.*}", handler)
+	k.Use(auditor.Middleware, authenticate)
+	s := router.PathPrefix("/services").Subrouter()
+	s.HandleFunc("/{tunnel_id}/{namespace}/{service}:{port:[0-9]+}/{service_uri:.*}", handler)
+	s.Use(auditor.Middleware, authenticate)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("requestresponse")
+	assert.NoError(t, err)
+	assert.Equal(t, auditv1.LevelRequestResponse, level)
+
+	_, err = ParseLevel("Everything")
+	assert.Error(t, err)
+
+	assert.False(t, New(auditv1.LevelNone, &memorySink{}).Enabled())
+	assert.False(t, New(auditv1.LevelMetadata).Enabled())
+	assert.True(t, New(auditv1.LevelMetadata, &memorySink{}).Enabled())
+}
+
+func TestMiddlewareMetadata(t *testing.T) {
+	sink := &memorySink{}
+	auditor := New(auditv1.LevelMetadata, sink)
+
+	req := httptest.NewRequest(http.MethodDelete, "/kubernetes/"+testTunnelID+"/apis/apps/v1/namespaces/default/deployments/nginx", nil)
+	serve(auditor, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}, req, "0a2b")
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, auditv1.StageResponseComplete, event.Stage)
+	assert.Equal(t, auditv1.LevelMetadata, event.Level)
+	assert.Equal(t, "delete", event.Verb)
+	assert.Equal(t, "/apis/apps/v1/namespaces/default/deployments/nginx", event.RequestURI)
+	assert.Equal(t, "alice", event.User.Username)
+	assert.Equal(t, "0a2b", event.User.UID)
+	assert.Equal(t, &auditv1.ObjectReference{
+		Resource:   "deployments",
+		Namespace:  "default",
+		Name:       "nginx",
+		APIGroup:   "apps",
+		APIVersion: "v1",
+	}, event.ObjectRef)
+	assert.EqualValues(t, http.StatusForbidden, event.ResponseStatus.Code)
+	assert.Equal(t, testTunnelID, event.Annotations[TunnelIDAnnotation])
+	assert.Equal(t, "0c6f1c38-8bd6-4bc1-a1ac-e7d2ee43ea1f", event.Annotations[ProjectIDAnnotation])
+	assert.NotEmpty(t, event.Annotations[LatencyAnnotation])
+	assert.Nil(t, event.RequestObject)
+	assert.Nil(t, event.ResponseObject)
+}
+
+func TestMiddlewareRequestResponse(t *testing.T) {
+	sink := &memorySink{}
+	auditor := New(auditv1.LevelRequestResponse, sink)
+
+	body := `{"kind":"ServiceAccount","apiVersion":"v1","metadata":{"name":"test"}}`
+	req := httptest.NewRequest(http.MethodPost, "/kubernetes/"+testTunnelID+"/api/v1/namespaces/default/serviceaccounts", strings.NewReader(body))
+	serve(auditor, func(w http.ResponseWriter, r *http.Request) {
+		received, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(received)
+	}, req, "")
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, "create", event.Verb)
+	assert.Empty(t, event.User.Username)
+	assert.EqualValues(t, http.StatusCreated, event.ResponseStatus.Code)
+	require.NotNil(t, event.RequestObject)
+	assert.JSONEq(t, body, string(event.RequestObject.Raw))
+	require.NotNil(t, event.ResponseObject)
+	assert.JSONEq(t, body, string(event.ResponseObject.Raw))
+}
+
+func TestMiddlewareSecrets(t *testing.T) {
+	sink := &memorySink{}
+	auditor := New(auditv1.LevelRequestResponse, sink)
+
+	// The data of the Secrets and ConfigMaps is never recorded.
+	for _, resource := range []string{"secrets", "configmaps"} {
+		body := `{"apiVersion":"v1","metadata":{"name":"test"},"data":{"password":"cGFzc3dvcmQ="}}`
+		req := httptest.NewRequest(http.MethodPost, "/kubernetes/"+testTunnelID+"/api/v1/namespaces/default/"+resource, strings.NewReader(body))
+		serve(auditor, func(w http.ResponseWriter, r *http.Request) {
+			received, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write(received)
+		}, req, "0a2b")
+	}
+
+	require.Len(t, sink.events, 2)
+	for _, event := range sink.events {
+		assert.Equal(t, auditv1.LevelMetadata, event.Level)
+		assert.Nil(t, event.RequestObject)
+		assert.Nil(t, event.ResponseObject)
+	}
+}
+
+func TestMiddlewareUnauthenticated(t *testing.T) {
+	sink := &memorySink{}
+	auditor := New(auditv1.LevelMetadata, sink)
+
+	// The requests rejected by the authentication are recorded, without a user.
+	router := mux.NewRouter()
+	k := router.PathPrefix("/kubernetes").Subrouter()
+	k.HandleFunc("/{tunnel_id}/{kubernetes_uri:.*}", func(w http.ResponseWriter, r *http.Request) {})
+	k.Use(auditor.Middleware, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/kubernetes/"+testTunnelID+"/api/v1/namespaces/default/pods", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, "list", sink.events[0].Verb)
+	assert.Empty(t, sink.events[0].User.Username)
+	assert.EqualValues(t, http.StatusUnauthorized, sink.events[0].ResponseStatus.Code)
+}
+
+func TestMiddlewareUpgrade(t *testing.T) {
+	sink := &memorySink{}
+	auditor := New(auditv1.LevelRequestResponse, sink)
+
+	req := httptest.NewRequest(http.MethodPost, "/kubernetes/"+testTunnelID+"/api/v1/namespaces/default/pods/nginx/exec?command=sh", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	serve(auditor, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	}, req, "0a2b")
+
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, auditv1.StageResponseStarted, sink.events[0].Stage)
+	assert.Equal(t, auditv1.StageResponseComplete, sink.events[1].Stage)
+	assert.Equal(t, sink.events[0].AuditID, sink.events[1].AuditID)
+	for _, event := range sink.events {
+		assert.Equal(t, "alice", event.User.Username)
+		assert.EqualValues(t, http.StatusSwitchingProtocols, event.ResponseStatus.Code)
+		assert.Equal(t, "exec", event.Annotations[UpgradeAnnotation])
+		assert.Equal(t, "pods", event.ObjectRef.Resource)
+		assert.Equal(t, "exec", event.ObjectRef.Subresource)
+	}
+}
+
+func TestMiddlewareService(t *testing.T) {
+	sink := &memorySink{}
+	auditor := New(auditv1.LevelMetadata, sink)
+
+	req := httptest.NewRequest(http.MethodGet, "/services/"+testTunnelID+"/monitoring/grafana:80/api/dashboards?limit=10", nil)
+	serve(auditor, func(w http.ResponseWriter, r *http.Request) {}, req, "0a2b")
+
+	require.Len(t, sink.events, 1)
+	event := sink.events[0]
+	assert.Equal(t, "/api/v1/namespaces/monitoring/services/grafana:80/proxy/api/dashboards?limit=10", event.RequestURI)
+	assert.Equal(t, "get", event.Verb)
+	assert.Equal(t, &auditv1.ObjectReference{
+		Resource:    "services",
+		Namespace:   "monitoring",
+		Name:        "grafana:80",
+		APIVersion:  "v1",
+		Subresource: "proxy",
+	}, event.ObjectRef)
+	assert.Equal(t, testTunnelID, event.Annotations[TunnelIDAnnotation])
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path, 1, 2)
+	require.NoError(t, err)
+
+	// Each event is a bit over 100KiB, so that the file is rotated every 10 events.
+	event := &auditv1.Event{AuditID: "test", RequestURI: strings.Repeat("a", 100*1024)}
+	for range 35 {
+		sink.ProcessEvent(event)
+	}
+	require.NoError(t, sink.Close())
+
+	lines := func(path string) int {
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		n := 0
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			decoded := &auditv1.Event{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), decoded))
+			n++
+		}
+		return n
+	}
+	assert.Equal(t, 5, lines(path))
+	assert.Equal(t, 10, lines(path+".1"))
+	assert.Equal(t, 10, lines(path+".2"))
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var received []auditv1.Event
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list := &auditv1.EventList{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(list))
+		assert.Equal(t, "EventList", list.Kind)
+		assert.LessOrEqual(t, len(list.Items), webhookBatchMaxSize)
+		mu.Lock()
+		received = append(received, list.Items...)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	sink := NewWebhookSink(webhook.URL, webhook.Client())
+	for range 250 {
+		sink.ProcessEvent(&auditv1.Event{AuditID: "test"})
+	}
+	require.NoError(t, sink.Close())
+
+	// The pending events are sent on Close, and the later events are dropped.
+	sink.ProcessEvent(&auditv1.Event{AuditID: "test"})
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, received, 250)
+}
@@ -0,0 +1,249 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+)
+
+const (
+	webhookBufferSize   = 10000
+	webhookBatchMaxSize = 100
+	webhookBatchMaxWait = time.Second
+)
+
+// logSink writes the events as JSON lines.
+type logSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// NewLogSink creates a Sink that writes the events as JSON lines to a given writer, e.g. os.Stdout.
+func NewLogSink(w io.Writer) Sink {
+	return &logSink{name: "log", w: w}
+}
+
+func (s *logSink) ProcessEvent(event *auditv1.Event) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		log.Errorf("Failed to encode audit event %s: %v", event.AuditID, err)
+		metrics.AuditEventsCounter.WithLabelValues(s.name, "failed").Inc()
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		log.Errorf("Failed to write audit event %s: %v", event.AuditID, err)
+		metrics.AuditEventsCounter.WithLabelValues(s.name, "failed").Inc()
+		return
+	}
+	metrics.AuditEventsCounter.WithLabelValues(s.name, "written").Inc()
+}
+
+func (s *logSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if closer, ok := s.w.(io.Closer); ok && s.w != os.Stdout && s.w != os.Stderr {
+		return closer.Close()
+	}
+	return nil
+}
+
+// NewFileSink creates a Sink that writes the events as JSON lines to a given file. The file is rotated once it
+// exceeds maxSize megabytes, and at most maxBackups rotated files are kept.
+func NewFileSink(path string, maxSize, maxBackups int) (Sink, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    int64(maxSize) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return &logSink{name: "file", w: f}, nil
+}
+
+// rotatingFile is a file that is renamed to <path>.1 once it exceeds a maximum size, shifting the previous
+// <path>.N files to <path>.N+1.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	backup := func(i int) string { return fmt.Sprintf("%s.%d", f.path, i) }
+	if f.maxBackups > 0 {
+		for i := f.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(backup(i), backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(f.path, backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *rotatingFile) Close() error {
+	return f.file.Close()
+}
+
+// webhookSink posts the events in batches to a webhook, as an audit.k8s.io/v1 EventList.
+type webhookSink struct {
+	url    string
+	client *http.Client
+	events chan *auditv1.Event
+	done   chan struct{}
+
+	// mu guards closed against the events sent while closing.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWebhookSink creates a Sink that posts the events to a given URL in the background. The events are dropped
+// if the webhook can't keep up.
+func NewWebhookSink(url string, client *http.Client) Sink {
+	s := &webhookSink{
+		url:    url,
+		client: client,
+		events: make(chan *auditv1.Event, webhookBufferSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *webhookSink) ProcessEvent(event *auditv1.Event) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		metrics.AuditEventsCounter.WithLabelValues("webhook", "dropped").Inc()
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+		log.Warnf("Dropping audit event %s, the webhook buffer is full", event.AuditID)
+		metrics.AuditEventsCounter.WithLabelValues("webhook", "dropped").Inc()
+	}
+}
+
+func (s *webhookSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+	s.mu.Unlock()
+	<-s.done
+	return nil
+}
+
+func (s *webhookSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(webhookBatchMaxWait)
+	defer ticker.Stop()
+
+	var batch []auditv1.Event
+	for {
+		select {
+		case event, ok := <-s.events:
+			if !ok {
+				s.send(batch)
+				return
+			}
+			batch = append(batch, *event)
+			if len(batch) < webhookBatchMaxSize {
+				continue
+			}
+		case <-ticker.C:
+		}
+		s.send(batch)
+		batch = nil
+	}
+}
+
+func (s *webhookSink) send(batch []auditv1.Event) {
+	if len(batch) == 0 {
+		return
+	}
+	result := "written"
+	if err := s.post(batch); err != nil {
+		log.Errorf("Failed to send %d audit events to the webhook: %v", len(batch), err)
+		result = "failed"
+	}
+	metrics.AuditEventsCounter.WithLabelValues("webhook", result).Add(float64(len(batch)))
+}
+
+func (s *webhookSink) post(batch []auditv1.Event) error {
+	body, err := json.Marshal(&auditv1.EventList{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "EventList",
+			APIVersion: auditv1.SchemeGroupVersion.String(),
+		},
+		Items: batch,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ package auth
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang-jwt/jwt/v5/request"
@@ -62,7 +63,7 @@ func (s *SecretTokenAuthorizer) Authorizer(req *http.Request) (clientKey string,
 	if err != nil {
 		return id, false, err
 	}
-	if token.Value == authToken {
+	if token.Accepts(authToken, time.Now()) {
 		return id, true, nil
 	}
 	return id, false, nil
@@ -9,6 +9,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 )
 
@@ -83,6 +85,24 @@ func (_m *MockTokenManager) GetToken(ctx context.Context, tunnelID string) (*aut
 	return r0, r1
 }
 
+// RefreshToken provides a mock function with given fields: ctx, tunnelID, gracePeriod
+func (_m *MockTokenManager) RefreshToken(ctx context.Context, tunnelID string, gracePeriod time.Duration) error {
+	ret := _m.Called(ctx, tunnelID, gracePeriod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) error); ok {
+		r0 = rf(ctx, tunnelID, gracePeriod)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // TokenExist provides a mock function with given fields: ctx, tunnelID
 func (_m *MockTokenManager) TokenExist(ctx context.Context, tunnelID string) (bool, error) {
 	ret := _m.Called(ctx, tunnelID)
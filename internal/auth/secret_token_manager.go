@@ -7,27 +7,67 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 
 	"github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
 )
 
 const (
 	DefaultSecretNamespace = "connect-gateway-secrets"
 	DefaultTokenLength     = 54
+
+	// TokenSecretNameSuffix is appended to the tunnel ID to name the token Secret.
+	TokenSecretNameSuffix = "-agent-token"
+
+	// TokenSecretLabel labels the token Secrets, so that only they are cached.
+	TokenSecretLabel = "cluster.edge-orchestrator.intel.com/agent-token"
+
+	// MaxTunnelIDLength is the longest tunnel ID that still results in a valid token Secret name.
+	MaxTunnelIDLength = validation.DNS1123SubdomainMaxLength - len(TokenSecretNameSuffix)
+
+	// Keys of the token Secret data.
+	tokenKey             = "token"
+	expiresAtKey         = "expiresAt"
+	previousTokenKey     = "previousToken"
+	previousExpiresAtKey = "previousTokenExpiresAt"
 )
 
 var GetClusterConfig = rest.InClusterConfig
 
+// TokenManagerOption configures the TokenManager created by NewTokenManager.
+type TokenManagerOption func(*manager)
+
+// WithTokenTTL sets the time-to-live of the created tokens. The tokens never expire if it is zero.
+func WithTokenTTL(ttl time.Duration) TokenManagerOption {
+	return func(m *manager) {
+		m.ttl = ttl
+	}
+}
+
+// WithCache serves the token lookups from a Secret informer running until a given context is done,
+// instead of getting the Secret from the API server on every lookup.
+func WithCache(ctx context.Context) TokenManagerOption {
+	return func(m *manager) {
+		m.cacheCtx = ctx
+	}
+}
+
 // NewInClusterSecretTokenManager creates a new TokenManager implementation
 // that uses local Kubernetes Secrets as a store for the token.
-func NewTokenManager() (TokenManager, error) {
+func NewTokenManager(options ...TokenManagerOption) (TokenManager, error) {
 	restconfig, err := GetClusterConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to obtain in-cluster config %v", err)
@@ -38,30 +78,100 @@ func NewTokenManager() (TokenManager, error) {
 		return nil, fmt.Errorf("failed to create kubernetes client %v", err)
 	}
 
-	namespace := DefaultSecretNamespace
-	ns, ok := os.LookupEnv("SECRET_NAMESPACE")
-	if ok && ns != "" {
-		namespace = ns
-	}
+	return newManager(clientset, SecretNamespace(), options...)
+}
 
-	return &manager{
+func newManager(clientset kubernetes.Interface, namespace string, options ...TokenManagerOption) (*manager, error) {
+	m := &manager{
 		client:    clientset.CoreV1().Secrets(namespace),
 		namespace: namespace,
-	}, nil
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	if m.cacheCtx != nil {
+		if err := m.startCache(clientset); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
 }
 
 // manager is the implementation of TokenManager interface
 type manager struct {
 	client    v1.SecretInterface
 	namespace string
+	ttl       time.Duration
+
+	// cache holds the Secrets of the namespace, if enabled.
+	cacheCtx context.Context
+	cache    corev1listers.SecretNamespaceLister
+}
+
+// startCache starts an informer of the token Secrets in the namespace of the manager and waits for its initial sync.
+func (m *manager) startCache(clientset kubernetes.Interface) error {
+	if err := m.labelTokenSecrets(m.cacheCtx); err != nil {
+		return err
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithNamespace(m.namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = TokenSecretLabel
+		}))
+	lister := factory.Core().V1().Secrets().Lister()
+
+	factory.Start(m.cacheCtx.Done())
+	for informerType, synced := range factory.WaitForCacheSync(m.cacheCtx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync informer cache for %v", informerType)
+		}
+	}
 
-	// TODO: Implement cache
-	// cache     sync.Map
+	m.cache = lister.Secrets(m.namespace)
+	return nil
+}
+
+// labelTokenSecrets labels the token Secrets created without TokenSecretLabel by former versions, so that they are
+// cached as well.
+func (m *manager) labelTokenSecrets(ctx context.Context) error {
+	secrets, err := m.client.List(ctx, metav1.ListOptions{LabelSelector: "!" + TokenSecretLabel})
+	if err != nil {
+		return fmt.Errorf("failed to list unlabeled token secrets (%v)", err)
+	}
+
+	patch := fmt.Appendf(nil, `{"metadata":{"labels":{%q:"true"}}}`, TokenSecretLabel)
+	for _, secret := range secrets.Items {
+		if !strings.HasSuffix(secret.Name, TokenSecretNameSuffix) || len(secret.Data[tokenKey]) == 0 {
+			continue
+		}
+		if _, err := m.client.Patch(ctx, secret.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to label token secret %s (%v)", secret.Name, err)
+		}
+	}
+	return nil
+}
+
+// getSecret returns the token Secret for a given tunnel ID.
+// The cache is authoritative once synced, so that unknown tunnels don't hit the API server either.
+// The returned Secret must not be modified, as it may be shared with the cache.
+func (m *manager) getSecret(ctx context.Context, tunnelID string) (*corev1.Secret, error) {
+	if m.cache == nil {
+		return m.client.Get(ctx, getTokenSecretName(tunnelID), metav1.GetOptions{})
+	}
+
+	secret, err := m.cache.Get(getTokenSecretName(tunnelID))
+	if err != nil {
+		metrics.TokenCacheCounter.WithLabelValues("miss").Inc()
+		return nil, err
+	}
+	metrics.TokenCacheCounter.WithLabelValues("hit").Inc()
+	return secret, nil
 }
 
 // TokenExist returns true if the token secret for a given tunnel ID exists
 func (m *manager) TokenExist(ctx context.Context, tunnelID string) (bool, error) {
-	if _, err := m.client.Get(ctx, getTokenSecretName(tunnelID), metav1.GetOptions{}); err != nil {
+	if _, err := m.getSecret(ctx, tunnelID); err != nil {
 		if apierrors.IsNotFound(err) {
 			return false, nil
 		} else {
@@ -73,15 +183,13 @@ func (m *manager) TokenExist(ctx context.Context, tunnelID string) (bool, error)
 
 // GetTokenSecretWithTunnelID returns the token value for a given tunnel ID.
 func (m *manager) GetToken(ctx context.Context, tunnelID string) (*Token, error) {
-	// Find it from Cache. If not exists or expired, retrieve from K8s Secret.
-
 	// Attempt to get secret
-	secret, err := m.client.Get(ctx, getTokenSecretName(tunnelID), metav1.GetOptions{})
+	secret, err := m.getSecret(ctx, tunnelID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get token for %s (%v)", tunnelID, err)
 	}
 
-	return &Token{Value: string(secret.Data["token"])}, nil
+	return tokenFromSecret(secret)
 }
 
 // CreateAndStoreToken generates a token value and create a Secret with it for a given tunnel ID.
@@ -95,6 +203,7 @@ func (m *manager) CreateAndStoreToken(ctx context.Context, tunnelID string, cc *
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      getTokenSecretName(tunnelID),
 			Namespace: m.namespace,
+			Labels:    map[string]string{TokenSecretLabel: "true"},
 			OwnerReferences: []metav1.OwnerReference{
 				{
 					APIVersion: v1alpha1.GroupVersion.String(),
@@ -105,10 +214,12 @@ func (m *manager) CreateAndStoreToken(ctx context.Context, tunnelID string, cc *
 			},
 		},
 		Data: map[string][]byte{
-			"token": []byte(token),
-			// TODO: Add expiration
+			tokenKey: []byte(token),
 		},
 	}
+	if m.ttl > 0 {
+		secret.Data[expiresAtKey] = formatTime(time.Now().Add(m.ttl))
+	}
 
 	if _, err := m.client.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
 		if !apierrors.IsAlreadyExists(err) {
@@ -127,9 +238,91 @@ func (m *manager) DeleteToken(ctx context.Context, tunnelID string) error {
 	return nil
 }
 
+// RefreshToken generates a new token value for a given tunnel ID.
+// The previous token is still accepted for the grace period, but not after its own expiration.
+// The previous token is revoked immediately if the grace period is zero.
+func (m *manager) RefreshToken(ctx context.Context, tunnelID string, gracePeriod time.Duration) error {
+	secret, err := m.client.Get(ctx, getTokenSecretName(tunnelID), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get token for %s (%v)", tunnelID, err)
+	}
+
+	current, err := tokenFromSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	token, err := GenerateToken(DefaultTokenLength)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	data := map[string][]byte{
+		tokenKey: []byte(token),
+	}
+	if m.ttl > 0 {
+		data[expiresAtKey] = formatTime(now.Add(m.ttl))
+	}
+
+	previousExpiresAt := now.Add(gracePeriod)
+	if !current.ExpiresAt.IsZero() && current.ExpiresAt.Before(previousExpiresAt) {
+		previousExpiresAt = current.ExpiresAt
+	}
+	if gracePeriod > 0 && previousExpiresAt.After(now) {
+		data[previousTokenKey] = []byte(current.Value)
+		data[previousExpiresAtKey] = formatTime(previousExpiresAt)
+	}
+
+	// The update fails on conflict, so that a concurrent rotation is never lost.
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[TokenSecretLabel] = "true"
+	secret.Data = data
+	if _, err := m.client.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update token secret (%v)", err)
+	}
+	return nil
+}
+
+// SecretNamespace returns the namespace of the Secrets managed for the tunnels, such as the token Secrets.
+func SecretNamespace() string {
+	if ns, ok := os.LookupEnv("SECRET_NAMESPACE"); ok && ns != "" {
+		return ns
+	}
+	return DefaultSecretNamespace
+}
+
 // GetTokenSecretName returns the token secret name for a given ClusterConnect object.
 func getTokenSecretName(tunnelId string) string {
-	// TODO: need to think about a case where tunnel ID exceeds 247 characters
-	// which makes the Secret name exceeds K8s resource name limit 253
-	return tunnelId + "-agent-token"
+	// Tunnel IDs longer than MaxTunnelIDLength are rejected by the ClusterConnect webhook.
+	return tunnelId + TokenSecretNameSuffix
+}
+
+func tokenFromSecret(secret *corev1.Secret) (*Token, error) {
+	token := &Token{
+		Value:         string(secret.Data[tokenKey]),
+		PreviousValue: string(secret.Data[previousTokenKey]),
+	}
+
+	var err error
+	if token.ExpiresAt, err = parseTime(secret.Data[expiresAtKey]); err != nil {
+		return nil, fmt.Errorf("invalid expiration of token secret %s (%v)", secret.Name, err)
+	}
+	if token.PreviousExpiresAt, err = parseTime(secret.Data[previousExpiresAtKey]); err != nil {
+		return nil, fmt.Errorf("invalid expiration of previous token in secret %s (%v)", secret.Name, err)
+	}
+	return token, nil
+}
+
+func formatTime(t time.Time) []byte {
+	return []byte(t.UTC().Format(time.RFC3339))
+}
+
+func parseTime(value []byte) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, string(value))
 }
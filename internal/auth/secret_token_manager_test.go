@@ -6,9 +6,11 @@ package auth
 import (
 	"context"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
@@ -60,6 +62,7 @@ func TestCreateAndStoreToken(t *testing.T) {
 		t.Errorf("Failed to get token: %v", err)
 	}
 
+	assert.Equal(t, "true", secret.Labels[TokenSecretLabel])
 	assert.NotEmpty(t, secret.GetOwnerReferences())
 	assert.Equal(t, string(secret.GetOwnerReferences()[0].UID), "fake-uid", "Token secret owner UID mismatch")
 	assert.Equal(t, secret.GetOwnerReferences()[0].Name, testTunnelID, "Token secret owner name mismatch")
@@ -125,3 +128,127 @@ func TestDeleteToken(t *testing.T) {
 		t.Errorf("Get secret should fail but succeed")
 	}
 }
+
+func TestCreateAndStoreTokenWithTTL(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	tokenManager := &manager{client: fakeClient.CoreV1().Secrets("test-ns"), namespace: "test-ns", ttl: time.Hour}
+
+	cc := &v1alpha1.ClusterConnect{ObjectMeta: metav1.ObjectMeta{Name: testTunnelID}}
+	assert.NoError(t, tokenManager.CreateAndStoreToken(context.TODO(), testTunnelID, cc))
+
+	token, err := tokenManager.GetToken(context.TODO(), testTunnelID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token.Value)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+	assert.Empty(t, token.PreviousValue)
+}
+
+func TestRefreshToken(t *testing.T) {
+	newSecret := func() *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test-ns",
+				Name:      getTokenSecretName(testTunnelID),
+			},
+			Data: map[string][]byte{
+				"token":     []byte("mockToken"),
+				"expiresAt": formatTime(time.Now().Add(2 * time.Hour)),
+			},
+		}
+	}
+
+	t.Run("keeps the previous token for the grace period", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newSecret())
+		tokenManager := &manager{client: fakeClient.CoreV1().Secrets("test-ns"), namespace: "test-ns", ttl: 24 * time.Hour}
+
+		assert.NoError(t, tokenManager.RefreshToken(context.TODO(), testTunnelID, time.Hour))
+
+		token, err := tokenManager.GetToken(context.TODO(), testTunnelID)
+		assert.NoError(t, err)
+		assert.NotEqual(t, "mockToken", token.Value)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), token.ExpiresAt, time.Minute)
+		assert.Equal(t, "mockToken", token.PreviousValue)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), token.PreviousExpiresAt, time.Minute)
+		assert.True(t, token.Accepts("mockToken", time.Now()))
+	})
+
+	t.Run("doesn't extend the previous token beyond its expiration", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newSecret())
+		tokenManager := &manager{client: fakeClient.CoreV1().Secrets("test-ns"), namespace: "test-ns", ttl: 24 * time.Hour}
+
+		assert.NoError(t, tokenManager.RefreshToken(context.TODO(), testTunnelID, 48*time.Hour))
+
+		token, err := tokenManager.GetToken(context.TODO(), testTunnelID)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), token.PreviousExpiresAt, time.Minute)
+	})
+
+	t.Run("revokes the previous token without grace period", func(t *testing.T) {
+		fakeClient := fake.NewSimpleClientset(newSecret())
+		tokenManager := &manager{client: fakeClient.CoreV1().Secrets("test-ns"), namespace: "test-ns"}
+
+		assert.NoError(t, tokenManager.RefreshToken(context.TODO(), testTunnelID, 0))
+
+		token, err := tokenManager.GetToken(context.TODO(), testTunnelID)
+		assert.NoError(t, err)
+		assert.True(t, token.ExpiresAt.IsZero())
+		assert.Empty(t, token.PreviousValue)
+		assert.False(t, token.Accepts("mockToken", time.Now()))
+	})
+
+	t.Run("fails without the token secret", func(t *testing.T) {
+		tokenManager := &manager{client: fake.NewSimpleClientset().CoreV1().Secrets("test-ns"), namespace: "test-ns"}
+		assert.Error(t, tokenManager.RefreshToken(context.TODO(), testTunnelID, time.Hour))
+	})
+}
+
+func TestTokenCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	// The token Secret is created without label, as by former versions, next to another Secret.
+	fakeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      getTokenSecretName(testTunnelID),
+		},
+		Data: map[string][]byte{
+			"token": []byte("mockToken"),
+		},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "other",
+		},
+	})
+
+	tokenManager, err := newManager(fakeClient, "test-ns", WithCache(ctx))
+	assert.NoError(t, err)
+
+	// Only the token Secret is cached, once labeled.
+	cached, err := tokenManager.cache.List(labels.Everything())
+	assert.NoError(t, err)
+	assert.Len(t, cached, 1)
+
+	token, err := tokenManager.GetToken(ctx, testTunnelID)
+	assert.NoError(t, err)
+	assert.Equal(t, "mockToken", token.Value)
+
+	exists, err := tokenManager.TokenExist(ctx, "unknown-tunnel")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	// A rotated token is served from the cache once the informer observes the update.
+	assert.NoError(t, tokenManager.RefreshToken(ctx, testTunnelID, time.Hour))
+	assert.Eventually(t, func() bool {
+		token, err := tokenManager.GetToken(ctx, testTunnelID)
+		return err == nil && token.Value != "mockToken" && token.PreviousValue == "mockToken"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A deleted token is rejected once the informer observes the deletion.
+	assert.NoError(t, tokenManager.DeleteToken(ctx, testTunnelID))
+	assert.Eventually(t, func() bool {
+		exists, err := tokenManager.TokenExist(ctx, testTunnelID)
+		return err == nil && !exists
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -6,7 +6,9 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/hex"
+	"time"
 
 	"github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 )
@@ -19,15 +21,39 @@ type TokenManager interface {
 	TokenExist(ctx context.Context, tunnelID string) (bool, error)                                                          // TokenExist returns true if the token for a given tunnel ID alreay exists.
 	CreateAndStoreToken(ctx context.Context, tunnelID string /* , tokenTTLHours int, */, cc *v1alpha1.ClusterConnect) error // CreateToken creates and stores a token with its value and TTL in hours.
 	DeleteToken(ctx context.Context, tunnelID string) error                                                                 // DeleteToken deletes a token for a given tunnel ID.
-	// RefreshToken(ctx context.Context, tunnelID string, tokenTTLHours int) error // TODO: implement
+	RefreshToken(ctx context.Context, tunnelID string, gracePeriod time.Duration) error                                     // RefreshToken replaces the token for a given tunnel ID, keeping the previous one valid for the grace period.
 }
 
-// Token struct represents a token with its value, updated time, and time-to-live in hours.
+// Token struct represents a token with its value and expiration.
 type Token struct {
 	Value string
 
-	// TODO: Add expiration
-	// Expire time.Time
+	// ExpiresAt is the time the token expires at. The token never expires if it is zero.
+	ExpiresAt time.Time
+
+	// PreviousValue is the token replaced by the last rotation, which is still accepted until PreviousExpiresAt.
+	PreviousValue     string
+	PreviousExpiresAt time.Time
+}
+
+// Expired returns true if the token is expired at a given time.
+func (t *Token) Expired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// Accepts returns true if a given value matches the token, or the previous token within its grace period.
+func (t *Token) Accepts(value string, now time.Time) bool {
+	if value == "" {
+		return false
+	}
+	if equal(value, t.Value) && !t.Expired(now) {
+		return true
+	}
+	return t.PreviousValue != "" && equal(value, t.PreviousValue) && now.Before(t.PreviousExpiresAt)
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
 
 // GenerateToken generates a random string to be used as a token for authenticating the connect-agent.
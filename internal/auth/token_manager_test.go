@@ -4,6 +4,7 @@ package auth_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -16,3 +17,31 @@ func TestGenerateToken(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, size*2, len(token)) // Token length in hex encoding
 }
+
+func TestTokenAccepts(t *testing.T) {
+	now := time.Now()
+	token := &auth.Token{
+		Value:             "new",
+		ExpiresAt:         now.Add(time.Hour),
+		PreviousValue:     "old",
+		PreviousExpiresAt: now.Add(time.Minute),
+	}
+
+	assert.True(t, token.Accepts("new", now))
+	assert.True(t, token.Accepts("old", now))
+	assert.False(t, token.Accepts("other", now))
+	assert.False(t, token.Accepts("", now))
+
+	// The previous token is rejected after the grace period.
+	assert.True(t, token.Accepts("new", now.Add(2*time.Minute)))
+	assert.False(t, token.Accepts("old", now.Add(2*time.Minute)))
+
+	// The token is rejected once expired.
+	assert.True(t, token.Expired(now.Add(time.Hour)))
+	assert.False(t, token.Accepts("new", now.Add(time.Hour)))
+
+	// The token never expires without expiration time.
+	token = &auth.Token{Value: "new"}
+	assert.False(t, token.Expired(now.Add(24*365*time.Hour)))
+	assert.True(t, token.Accepts("new", now.Add(24*365*time.Hour)))
+}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	clusterv1 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/auth"
+)
+
+const (
+	// AutoConnectLabel marks the ClusterConnect objects created for a CAPI Cluster by the ClusterReconciler.
+	AutoConnectLabel = "cluster.edge-orchestrator.intel.com/auto-connect"
+)
+
+// ClusterReconciler creates a ClusterConnect for each CAPI Cluster selected by a label selector or an annotation,
+// and deletes it when the Cluster is deleted. The ClusterConnect has no owner reference, as the garbage collector
+// doesn't delete a cluster-scoped object with its namespaced owner.
+//
+// The ClusterConnect is named <cluster namespace>-<cluster name>, which is the <project UUID>-<name> tunnel ID format
+// expected by the gateway when the Cluster namespaces are named after the projects.
+type ClusterReconciler struct {
+	client.Client
+
+	// Selector selects the Clusters by their labels. No Cluster is selected by labels if it is nil.
+	Selector labels.Selector
+
+	// Annotation selects the Clusters that have the annotation set to "true". No Cluster is selected by annotation if it is empty.
+	Annotation string
+
+	recorder events.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.edge-orchestrator.intel.com,resources=clusterconnects,verbs=get;list;watch;create;delete
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		return errors.New("Client must not be nil")
+	}
+	if r.Selector == nil && r.Annotation == "" {
+		return errors.New("either Selector or Annotation must be set")
+	}
+	r.recorder = mgr.GetEventRecorder("cluster/autoconnect")
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&clusterv1.Cluster{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return r.selected(e.Object)
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return r.selected(e.ObjectNew)
+			},
+			DeleteFunc: func(_ event.DeleteEvent) bool {
+				// The Cluster may have been deselected before its deletion.
+				return true
+			},
+			GenericFunc: func(e event.GenericEvent) bool {
+				return r.selected(e.Object)
+			},
+		})).
+		// Watch the created ClusterConnect objects, so that the ones left behind by a Cluster deleted
+		// while the controller was down are deleted as well.
+		Watches(&v1alpha1.ClusterConnect{}, handler.EnqueueRequestsFromMapFunc(clusterConnectToCluster),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isAutoConnect))).
+		Named("cluster/autoconnect").
+		Complete(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+	return nil
+}
+
+// Reconcile creates the ClusterConnect of a selected Cluster, or deletes it once the Cluster is deleted.
+func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(ctx, req.NamespacedName, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, r.deleteClusterConnect(ctx, req.NamespacedName)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !cluster.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.deleteClusterConnect(ctx, req.NamespacedName)
+	}
+
+	if !r.selected(cluster) {
+		return ctrl.Result{}, nil
+	}
+
+	name := clusterConnectName(req.NamespacedName)
+	if len(name) > auth.MaxTunnelIDLength {
+		log.Info("Skipping Cluster whose tunnel ID would be too long", "tunnelID", name, "maxLength", auth.MaxTunnelIDLength)
+		return ctrl.Result{}, nil
+	}
+
+	cc := &v1alpha1.ClusterConnect{}
+	err := r.Client.Get(ctx, client.ObjectKey{Name: name}, cc)
+	if err == nil {
+		if !createdFor(cc, req.NamespacedName) {
+			log.Info("Skipping Cluster whose ClusterConnect name is taken by another ClusterConnect", "clusterConnect", name)
+			r.recorder.Eventf(cluster, cc, corev1.EventTypeWarning, "ClusterConnectNameConflict", "AutoConnect",
+				"ClusterConnect %s already exists and was not created for this Cluster", name)
+		}
+		return ctrl.Result{}, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("failed to get ClusterConnect %s: %v", name, err)
+	}
+
+	cc = &v1alpha1.ClusterConnect{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{AutoConnectLabel: "true"},
+		},
+		Spec: v1alpha1.ClusterConnectSpec{
+			ClusterRef: &corev1.ObjectReference{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Namespace:  cluster.Namespace,
+				Name:       cluster.Name,
+			},
+		},
+	}
+	if err := r.Client.Create(ctx, cc); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to create ClusterConnect %s: %v", name, err)
+	}
+
+	log.Info("Created ClusterConnect for Cluster", "clusterConnect", name)
+	return ctrl.Result{}, nil
+}
+
+// deleteClusterConnect deletes the ClusterConnect created for a given Cluster, if any.
+func (r *ClusterReconciler) deleteClusterConnect(ctx context.Context, cluster types.NamespacedName) error {
+	cc := &v1alpha1.ClusterConnect{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: clusterConnectName(cluster)}, cc); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	// Leave the ClusterConnect objects created by others, or for another Cluster, alone.
+	if !createdFor(cc, cluster) {
+		return nil
+	}
+	if !cc.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	if err := r.Client.Delete(ctx, cc); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete ClusterConnect %s: %v", cc.Name, err)
+	}
+	log.FromContext(ctx).Info("Deleted ClusterConnect of deleted Cluster", "clusterConnect", cc.Name)
+	return nil
+}
+
+// selected reports whether a given Cluster is selected by the label selector or the annotation.
+func (r *ClusterReconciler) selected(obj client.Object) bool {
+	if r.Selector != nil && !r.Selector.Empty() && r.Selector.Matches(labels.Set(obj.GetLabels())) {
+		return true
+	}
+	return r.Annotation != "" && obj.GetAnnotations()[r.Annotation] == "true"
+}
+
+// clusterConnectName returns the name of the ClusterConnect created for a given Cluster.
+func clusterConnectName(cluster types.NamespacedName) string {
+	return cluster.Namespace + "-" + cluster.Name
+}
+
+// isAutoConnect reports whether a given ClusterConnect was created by the ClusterReconciler.
+func isAutoConnect(obj client.Object) bool {
+	return obj.GetLabels()[AutoConnectLabel] == "true"
+}
+
+// createdFor reports whether a given ClusterConnect was created by the ClusterReconciler for a given Cluster.
+func createdFor(cc *v1alpha1.ClusterConnect, cluster types.NamespacedName) bool {
+	ref := cc.Spec.ClusterRef
+	return isAutoConnect(cc) && ref != nil && ref.Namespace == cluster.Namespace && ref.Name == cluster.Name
+}
+
+// clusterConnectToCluster maps a ClusterConnect to the Cluster it was created for.
+func clusterConnectToCluster(_ context.Context, obj client.Object) []ctrl.Request {
+	cc, ok := obj.(*v1alpha1.ClusterConnect)
+	if !ok || cc.Spec.ClusterRef == nil {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{
+		Namespace: cc.Spec.ClusterRef.Namespace,
+		Name:      cc.Spec.ClusterRef.Name,
+	}}}
+}
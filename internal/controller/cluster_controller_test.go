@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+)
+
+const testAutoConnectAnnotation = "cluster.edge-orchestrator.intel.com/connect"
+
+var _ = Describe("Cluster Controller", func() {
+	const (
+		timeout  = time.Second * 5
+		interval = time.Second * 1
+	)
+
+	newCluster := func(name string, annotations map[string]string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Spec: clusterv1.ClusterSpec{
+				ControlPlaneRef: clusterv1.ContractVersionedObjectReference{
+					APIGroup: "controlplane.cluster.x-k8s.io",
+					Kind:     "RKE2ControlPlane",
+					Name:     name,
+				},
+			},
+		}
+	}
+
+	Context("When a CAPI Cluster is annotated for auto-connect", func() {
+		It("should create the ClusterConnect and delete it with the Cluster", func() {
+			cl := newCluster("auto1", map[string]string{testAutoConnectAnnotation: "true"})
+			Expect(k8sClient.Create(ctx, cl)).To(Succeed())
+
+			cc := &v1alpha1.ClusterConnect{}
+			testClusterConnect := types.NamespacedName{Name: "default-auto1"}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, testClusterConnect, cc)
+			}, timeout, interval).Should(Succeed())
+			Expect(cc.Labels).To(HaveKeyWithValue(AutoConnectLabel, "true"))
+			Expect(cc.Spec.ClusterRef).NotTo(BeNil())
+			Expect(cc.Spec.ClusterRef.Namespace).To(Equal("default"))
+			Expect(cc.Spec.ClusterRef.Name).To(Equal("auto1"))
+			// The garbage collector doesn't delete a cluster-scoped object with a namespaced owner.
+			Expect(cc.OwnerReferences).To(BeEmpty())
+
+			Expect(k8sClient.Delete(ctx, cl)).To(Succeed())
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, testClusterConnect, cc))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When a CAPI Cluster is not selected for auto-connect", func() {
+		It("should not create a ClusterConnect", func() {
+			cl := newCluster("auto2", map[string]string{testAutoConnectAnnotation: "false"})
+			Expect(k8sClient.Create(ctx, cl)).To(Succeed())
+
+			Consistently(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: "default-auto2"}, &v1alpha1.ClusterConnect{}))
+			}, 3*time.Second, interval).Should(BeTrue())
+
+			Expect(k8sClient.Delete(ctx, cl)).To(Succeed())
+		})
+	})
+
+	Context("When the ClusterConnect name of a CAPI Cluster is taken", func() {
+		It("should leave the ClusterConnect alone and record a warning event on the Cluster", func() {
+			existing := &v1alpha1.ClusterConnect{ObjectMeta: metav1.ObjectMeta{Name: "default-auto3"}}
+			Expect(k8sClient.Create(ctx, existing)).To(Succeed())
+
+			cl := newCluster("auto3", map[string]string{testAutoConnectAnnotation: "true"})
+			Expect(k8sClient.Create(ctx, cl)).To(Succeed())
+
+			Eventually(func() bool {
+				list := &eventsv1.EventList{}
+				if err := k8sClient.List(ctx, list, client.InNamespace(cl.Namespace)); err != nil {
+					return false
+				}
+				for _, event := range list.Items {
+					if event.Regarding.Name == cl.Name && event.Reason == "ClusterConnectNameConflict" {
+						return event.Type == corev1.EventTypeWarning
+					}
+				}
+				return false
+			}, timeout, interval).Should(BeTrue())
+
+			cc := &v1alpha1.ClusterConnect{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "default-auto3"}, cc)).To(Succeed())
+			Expect(cc.Labels).NotTo(HaveKey(AutoConnectLabel))
+			Expect(cc.Spec.ClusterRef).To(BeNil())
+
+			// The ClusterConnect is not deleted with the Cluster.
+			Expect(k8sClient.Delete(ctx, cl)).To(Succeed())
+			Consistently(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{Name: "default-auto3"}, cc)
+			}, 3*time.Second, interval).Should(Succeed())
+			Expect(k8sClient.Delete(ctx, cc)).To(Succeed())
+		})
+	})
+})
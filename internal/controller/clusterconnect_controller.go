@@ -4,12 +4,15 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -21,9 +24,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/events"
 	clusterv1 "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	v1beta2conditions "sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,8 +43,10 @@ import (
 	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/agentconfig"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/auth"
+	metrics "github.com/open-edge-platform/cluster-connect-gateway/internal/metrics/controller"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/provider"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/kubeutil"
+	"github.com/open-edge-platform/cluster-connect-gateway/pkg/gatewayclient"
 )
 
 const (
@@ -48,10 +56,13 @@ const (
 
 	agentManifestPath   = "connect-agent.yaml"
 	privateCAEnabledEnv = "PRIVATE_CA_ENABLED"
+
+	deletedSessionReason = "ClusterConnect deleted"
 )
 
 var (
-	createOnlyPredicate = predicate.Funcs{
+	// kubeconfigSecretPredicate filters the kubeconfig Secret events that may require an update of the Secret.
+	kubeconfigSecretPredicate = predicate.Funcs{
 		CreateFunc: func(_ event.CreateEvent) bool {
 			return true
 		},
@@ -60,8 +71,15 @@ var (
 			return false
 		},
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			// no action
-			return false
+			oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+			if !ok {
+				return true
+			}
+			newSecret, ok := e.ObjectNew.(*corev1.Secret)
+			if !ok {
+				return true
+			}
+			return !reflect.DeepEqual(oldSecret.Data, newSecret.Data)
 		},
 		GenericFunc: func(_ event.GenericEvent) bool {
 			// no action
@@ -87,19 +105,29 @@ var (
 
 var (
 	clusterConnectConnectionProbeTimeout = 5 * time.Minute
+
+	// kubeconfigCorrectionInterval is the minimum interval between two corrections of a kubeconfig Secret
+	// that drifted from the gateway endpoint.
+	kubeconfigCorrectionInterval = 1 * time.Minute
+
+	// sessionCloseCheckInterval is the interval to check whether the gateway closed the session of a deleted ClusterConnect.
+	sessionCloseCheckInterval = 5 * time.Second
 )
 
 type ConnectAgentConfig struct {
-	Path    string `json:"path"`
-	Owner   string `json:"owner"`
-	Content string `json:"content"`
+	Path        string `json:"path"`
+	Owner       string `json:"owner"`
+	Permissions string `json:"permissions,omitempty"`
+	Content     string `json:"content"`
 }
 
 // +kubebuilder:rbac:groups=cluster.edge-orchestrator.intel.com,resources=clusterconnects,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.edge-orchestrator.intel.com,resources=clusterconnects/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.edge-orchestrator.intel.com,resources=clusterconnects/finalizers,verbs=update
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;patch;update
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;patch;update;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes;kthreescontrolplanes;rke2controlplanes,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 // ClusterConnectReconciler reconciles a ClusterConnect object
@@ -107,15 +135,34 @@ type ClusterConnectReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 
-	// TODO: Add pointer to connect-gateway client to request disconnect when
-	// ClusterConnect object is removed
-	// gatewayClient *gateway.Client
+	// AgentTokenTTL is the time-to-live of the agent tokens. The tokens never expire if it is zero.
+	// The tokens are rotated once less than a fifth of their lifetime remains.
+	AgentTokenTTL time.Duration
+
+	// AgentTokenGracePeriod is the period the previous agent token is still accepted after a rotation,
+	// to give time to propagate the new connect-agent manifest to the cluster.
+	AgentTokenGracePeriod time.Duration
+
+	// ConnectionProbeGracePeriod is the period after the creation a connection probe that never succeeded
+	// is not reported as failed, to give time to deploy the connect-agent. Defaults to the connection probe timeout.
+	ConnectionProbeGracePeriod time.Duration
+
 	tokenManager    auth.TokenManager
 	providerManager provider.ProviderManager
 
+	// gatewayClient is used to close the sessions of deleted ClusterConnect objects without delay.
+	// It is nil if the admin API of the gateway is not enabled.
+	gatewayClient *gatewayclient.Client
+
 	controlPlaneEndpointHost string
 	controlPlaneEndpointPort int32
 
+	// gatewayInternalURL and gatewayExternalURL are the base URLs of the connection gateway.
+	// gatewayExternalURL is nil if the gateway is not exposed.
+	gatewayInternalURL *url.URL
+	gatewayExternalURL *url.URL
+	gatewayCABundle    []byte
+
 	externalTracker external.ObjectTracker
 	recorder        events.EventRecorder
 }
@@ -146,18 +193,30 @@ func (r *ClusterConnectReconciler) SetupWithManager(ctx context.Context, mgr ctr
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
 
-	// TODO: initialize connect-gateway client
-
-	if r.tokenManager, err = auth.NewTokenManager(); err != nil {
+	if r.tokenManager, err = auth.NewTokenManager(auth.WithTokenTTL(r.AgentTokenTTL)); err != nil {
 		return errors.Wrap(err, "failed to initialize token manager")
 	}
 
-	// Initialize provider manager with KThreesControlPlane provider.
-	// Add KubeadmControlPlane when implemented.
-	r.providerManager = provider.NewProviderManager().
-		WithProvider("RKE2ControlPlane", "/var/lib/rancher/rke2/agent/pod-manifests/connect-agent.yaml").
-		WithProvider("KThreesControlPlane", "/var/lib/rancher/k3s/agent/pod-manifests/connect-agent.yaml").
-		Build()
+	// Initialize provider manager with the built-in ControlPlane providers, and the ones defined in the
+	// providers ConfigMap, if given, which are reloaded whenever the ConfigMap changes.
+	providerManagerBuilder := provider.NewProviderManager()
+	for _, p := range provider.DefaultProviders {
+		providerManagerBuilder.WithProvider(p)
+	}
+	if providersConfigMap := os.Getenv("CONTROL_PLANE_PROVIDERS_CONFIGMAP"); providersConfigMap != "" {
+		namespace, name, err := cache.SplitMetaNamespaceKey(providersConfigMap)
+		if err != nil || namespace == "" || name == "" {
+			return fmt.Errorf("invalid CONTROL_PLANE_PROVIDERS_CONFIGMAP: %s, expected <namespace>/<name>", providersConfigMap)
+		}
+		clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+		if err != nil {
+			return errors.Wrap(err, "failed to create kubernetes client")
+		}
+		providerManagerBuilder.WithConfigMap(clientset, namespace, name)
+	}
+	if r.providerManager, err = providerManagerBuilder.Build(ctx); err != nil {
+		return errors.Wrap(err, "failed to initialize provider manager")
+	}
 
 	// Get the hostname of the control plane endpoint from environment variable.
 	parsedURL, err := url.Parse(os.Getenv("GATEWAY_INTERNAL_URL"))
@@ -171,6 +230,26 @@ func (r *ClusterConnectReconciler) SetupWithManager(ctx context.Context, mgr ctr
 
 	r.controlPlaneEndpointHost = parsedURL.Hostname()
 	r.controlPlaneEndpointPort = int32(port) // nolint: gosec
+	r.gatewayInternalURL = parsedURL
+
+	// Get the external URL of the gateway, which is also used by the connect-agent, from environment variable.
+	if externalURL := os.Getenv("GATEWAY_EXTERNAL_URL"); externalURL != "" {
+		if r.gatewayExternalURL, err = toHTTPURL(externalURL); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("invalid GATEWAY_EXTERNAL_URL: %s", externalURL))
+		}
+	}
+	r.gatewayCABundle = []byte(os.Getenv("GATEWAY_CA"))
+
+	// Initialize the connect-gateway client, if the admin API token is given.
+	if tokenPath := os.Getenv("GATEWAY_ADMIN_TOKEN_PATH"); tokenPath != "" {
+		token, err := os.ReadFile(tokenPath)
+		if err != nil {
+			return errors.Wrap(err, "failed to read gateway admin token")
+		}
+		if r.gatewayClient, err = gatewayclient.New(r.gatewayInternalURL.String(), strings.TrimSpace(string(token))); err != nil {
+			return errors.Wrap(err, "failed to initialize gateway client")
+		}
+	}
 
 	// Add field indexer for spec.clusterRef field.
 	if err = mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.ClusterConnect{}, clusterRefKey, clusterRefIdxFunc); err != nil {
@@ -200,6 +279,7 @@ func (r *ClusterConnectReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	cc := &v1alpha1.ClusterConnect{}
 	if err := r.Client.Get(ctx, req.NamespacedName, cc); err != nil {
 		if apierrors.IsNotFound(err) {
+			metrics.DeleteClusterConnectState(req.Name)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -221,12 +301,16 @@ func (r *ClusterConnectReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	defer func() {
 		// Always reconcile the status.
-		r.updateStatus(ctx, cc)
+		firstReady := r.updateStatus(ctx, cc)
 
 		// Patch the updates after each reconciliation.
 		patchOpts := []patch.Option{patch.WithStatusObservedGeneration{}}
 		if err := patchHelper.Patch(ctx, cc, patchOpts...); err != nil {
 			reterr = kerrors.NewAggregate([]error{reterr, err})
+		} else if firstReady {
+			// The time to ready is observed once, when the first ready timestamp is recorded.
+			metrics.ClusterConnectTimeToReady.Observe(
+				cc.Status.FirstReadyTimestamp.Sub(cc.CreationTimestamp.Time).Seconds())
 		}
 
 		if reterr != nil {
@@ -248,7 +332,9 @@ func (r *ClusterConnectReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	return result, err
 }
 
-func (r *ClusterConnectReconciler) updateStatus(ctx context.Context, cc *v1alpha1.ClusterConnect) {
+// updateStatus reconciles the conditions and the readiness of a given ClusterConnect, and reports whether it became
+// ready for the first time.
+func (r *ClusterConnectReconciler) updateStatus(ctx context.Context, cc *v1alpha1.ClusterConnect) (firstReady bool) {
 	_ = log.FromContext(ctx)
 
 	// Check if conditions are initialized, if not, initialize them with Unknown.
@@ -262,13 +348,16 @@ func (r *ClusterConnectReconciler) updateStatus(ctx context.Context, cc *v1alpha
 		}
 	}
 
+	// Surface the suspension of the tunnel.
+	updateSuspendedCondition(cc)
+
 	// Set status.ready to true if all the conditions are true.
 	status := true
 	for _, condition := range cc.Status.Conditions {
 		// skip the condition that is not a part of the provisioning.
 		// Status.Ready value should be based only on the conditions
 		// that are part of the provisioning.
-		if condition.Type == v1alpha1.ConnectionProbeCondition {
+		if condition.Type == v1alpha1.ConnectionProbeCondition || condition.Type == v1alpha1.SuspendedCondition {
 			continue
 		}
 
@@ -278,25 +367,326 @@ func (r *ClusterConnectReconciler) updateStatus(ctx context.Context, cc *v1alpha
 		}
 	}
 
+	// Record the first time the ClusterConnect becomes ready, which measures how long the onboarding takes.
+	if cc.Status.FirstReadyTimestamp == nil && cc.ObjectMeta.DeletionTimestamp.IsZero() {
+		switch {
+		case cc.Status.Ready:
+			// The ClusterConnect was ready before the first ready timestamp was recorded, so it is backfilled with
+			// the time the conditions became true, and the time to ready is not observed.
+			cc.Status.FirstReadyTimestamp = readySince(cc)
+		case status:
+			now := metav1.Now()
+			cc.Status.FirstReadyTimestamp = &now
+			firstReady = true
+		}
+	}
+
 	cc.Status.Ready = status
+
+	if !cc.ObjectMeta.DeletionTimestamp.IsZero() {
+		metrics.DeleteClusterConnectState(cc.Name)
+	} else {
+		metrics.SetClusterConnectState(cc.Name, status, v1beta2conditions.IsTrue(cc, v1alpha1.ConnectionProbeCondition))
+	}
+	return firstReady
+}
+
+// readySince returns the last transition time of the provisioning conditions of a given ready ClusterConnect, which
+// is the time it became ready, or its creation time if it has no such condition.
+func readySince(cc *v1alpha1.ClusterConnect) *metav1.Time {
+	since := cc.CreationTimestamp
+	for _, condition := range cc.Status.Conditions {
+		if condition.Type == v1alpha1.ConnectionProbeCondition || condition.Type == v1alpha1.SuspendedCondition {
+			continue
+		}
+		if since.Before(&condition.LastTransitionTime) {
+			since = condition.LastTransitionTime
+		}
+	}
+	return &since
 }
 
-//nolint:unparam
 func (r *ClusterConnectReconciler) delete(ctx context.Context, cc *v1alpha1.ClusterConnect) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	log := log.FromContext(ctx)
 
 	if !cutil.ContainsFinalizer(cc, FinalizerConnectController) {
 		return ctrl.Result{}, nil
 	}
 
-	// TODO: Add finalizer logic
-	// 1) get connection from remotedialer server
-	// 2) close the connection if exists
-	cutil.RemoveFinalizer(cc, FinalizerConnectController)
+	// Cleanup consists of four steps. The finalizer is removed only when all of them are done.
+	// 1) Delete the auth token so that the connect-agent cannot reconnect
+	// 2) Remove the connect-agent config from the Cluster or ControlPlane object
+	// 3) Restore or delete the kubeconfig Secret pointing to the gateway
+	// 4) Wait until the gateway closes the session, which it does for ClusterConnect being deleted.
+	//    The session is closed without delay if the gateway client is available.
+	steps := []func(context.Context, *v1alpha1.ClusterConnect) error{
+		r.deleteAuthToken,
+		r.deleteConnectAgentConfig,
+		r.deleteKubeconfig,
+	}
+	r.disconnectSession(ctx, cc)
+
+	errs := []error{}
+	for _, step := range steps {
+		if err := step(ctx, cc); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errs)
+	}
+
+	// The session status is updated by the gateway replica holding the session.
+	// Give up waiting after the connection probe timeout, e.g. if the gateway replica is gone.
+	if session := cc.Status.Session; session != nil && session.Connected {
+		if elapsed := time.Since(cc.DeletionTimestamp.Time); elapsed < clusterConnectConnectionProbeTimeout {
+			log.Info("Waiting for the gateway to close the session", "replica", session.GatewayReplica)
+			return ctrl.Result{RequeueAfter: sessionCloseCheckInterval}, nil
+		}
+		log.Info("Timed out waiting for the gateway to close the session", "replica", session.GatewayReplica)
+	}
 
+	cutil.RemoveFinalizer(cc, FinalizerConnectController)
 	return ctrl.Result{}, nil
 }
 
+// disconnectSession requests the gateway to close the session and flush the cached client of the tunnel.
+// This is best effort, as the gateway replica serving the request may not hold the session.
+// The gateway replicas eventually close the sessions of ClusterConnect being deleted anyway.
+func (r *ClusterConnectReconciler) disconnectSession(ctx context.Context, cc *v1alpha1.ClusterConnect) {
+	log := log.FromContext(ctx)
+
+	if r.gatewayClient == nil {
+		return
+	}
+	if session := cc.Status.Session; session == nil || !session.Connected {
+		return
+	}
+
+	tunnelId := cc.GetTunnelID()
+	if err := r.gatewayClient.Disconnect(ctx, tunnelId, deletedSessionReason); err != nil && !errors.Is(err, gatewayclient.ErrNotFound) {
+		log.Info("Failed to request the gateway to close the session", "reason", err.Error())
+	}
+	if err := r.gatewayClient.FlushClient(ctx, tunnelId); err != nil && !errors.Is(err, gatewayclient.ErrNotFound) {
+		log.Info("Failed to request the gateway to flush the client", "reason", err.Error())
+	}
+}
+
+// deleteAuthToken deletes the token Secret of the tunnel.
+func (r *ClusterConnectReconciler) deleteAuthToken(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
+	tunnelId := cc.GetTunnelID()
+	exist, err := r.tokenManager.TokenExist(ctx, tunnelId)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %v", err)
+	}
+	if !exist {
+		return nil
+	}
+
+	if err := r.tokenManager.DeleteToken(ctx, tunnelId); err != nil {
+		return fmt.Errorf("failed to delete token: %v", err)
+	}
+	return nil
+}
+
+// deleteConnectAgentConfig removes the connectAgentManifest variable from the Cluster topology,
+// or the connect-agent file from the ControlPlane object in legacy mode.
+func (r *ClusterConnectReconciler) deleteConnectAgentConfig(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
+	log := log.FromContext(ctx)
+
+	// Return early if ClusterRef is not set in the ClusterConnect object.
+	if cc.Spec.ClusterRef == nil {
+		return nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	clusterKey := client.ObjectKey{
+		Namespace: cc.Spec.ClusterRef.Namespace,
+		Name:      cc.Spec.ClusterRef.Name,
+	}
+	if err := r.Client.Get(ctx, clusterKey, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get Cluster object %s/%s: %v", clusterKey.Namespace, clusterKey.Name, err)
+	}
+
+	// Nothing to clean up if the Cluster is being deleted along with the ClusterConnect.
+	if !cluster.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	if !cluster.Spec.Topology.IsDefined() {
+		return r.deleteLegacyModeConfig(ctx, cluster)
+	}
+
+	variables := []clusterv1.ClusterVariable{}
+	for _, variable := range cluster.Spec.Topology.Variables {
+		if variable.Name != "connectAgentManifest" {
+			variables = append(variables, variable)
+		}
+	}
+	if len(variables) == len(cluster.Spec.Topology.Variables) {
+		return nil
+	}
+
+	patchHelper, err := patch.NewHelper(cluster, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create patch helper for Cluster %s/%s: %v", clusterKey.Namespace, clusterKey.Name, err)
+	}
+
+	cluster.Spec.Topology.Variables = variables
+	if err := patchHelper.Patch(ctx, cluster); err != nil {
+		// The variable may be required by the ClusterClass. Don't block the deletion forever in this case.
+		if apierrors.IsInvalid(err) {
+			log.Info("Keeping connectAgentManifest variable in Cluster", "cluster", clusterKey, "reason", err.Error())
+			r.recorder.Eventf(cc, cluster, corev1.EventTypeWarning, "CleanupSkipped", "Delete",
+				"Failed to remove connectAgentManifest variable from Cluster %s/%s: %v", clusterKey.Namespace, clusterKey.Name, err)
+			return nil
+		}
+		return fmt.Errorf("failed to patch Cluster object %s/%s: %v", clusterKey.Namespace, clusterKey.Name, err)
+	}
+
+	log.Info("Removed connectAgentManifest variable from Cluster", "cluster", clusterKey)
+	return nil
+}
+
+// deleteLegacyModeConfig removes the connect-agent file from the ControlPlane object of a given Cluster.
+func (r *ClusterConnectReconciler) deleteLegacyModeConfig(ctx context.Context, cluster *clusterv1.Cluster) error {
+	log := log.FromContext(ctx)
+
+	if !cluster.Spec.ControlPlaneRef.IsDefined() {
+		return nil
+	}
+
+	controlPlaneRef := cluster.Spec.ControlPlaneRef
+	controlPlaneKey := client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name:      controlPlaneRef.Name,
+	}
+	controlPlaneMapping, err := r.Client.RESTMapper().RESTMapping(schema.GroupKind{
+		Group: controlPlaneRef.APIGroup,
+		Kind:  controlPlaneRef.Kind,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve ControlPlane mapping for %s.%s: %v", controlPlaneRef.Kind, controlPlaneRef.APIGroup, err)
+	}
+
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetGroupVersionKind(controlPlaneMapping.GroupVersionKind)
+	if err := r.Client.Get(ctx, controlPlaneKey, controlPlane); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get ControlPlane object %s/%s: %v", controlPlaneKey.Namespace, controlPlaneKey.Name, err)
+	}
+
+	controlPlaneProvider := r.controlPlaneProvider(cluster)
+	filesPath := controlPlaneProvider.FilesFields()
+	files, found, err := unstructured.NestedSlice(controlPlane.Object, filesPath...)
+	if err != nil || !found {
+		return nil
+	}
+
+	agentFilePath := legacyAgentFilePath(controlPlaneProvider)
+	remaining := []interface{}{}
+	for _, file := range files {
+		if isAgentManifestFile(file, agentFilePath) {
+			continue
+		}
+		remaining = append(remaining, file)
+	}
+	if len(remaining) == len(files) {
+		return nil
+	}
+
+	if err := unstructured.SetNestedSlice(controlPlane.Object, remaining, filesPath...); err != nil {
+		return fmt.Errorf("failed to remove connect-agent.yaml file from ControlPlane object %s/%s: %v", controlPlaneKey.Namespace, controlPlaneKey.Name, err)
+	}
+	if err := r.Client.Update(ctx, controlPlane); err != nil {
+		return fmt.Errorf("failed to update ControlPlane object %s/%s: %v", controlPlaneKey.Namespace, controlPlaneKey.Name, err)
+	}
+
+	log.Info("Removed connect-agent.yaml file from ControlPlane", "controlPlane", controlPlaneKey)
+	return nil
+}
+
+// deleteKubeconfig restores the kubeconfig Secret of a Cluster-API Cluster to point to the actual control plane endpoint.
+// The kubeconfig Secret is deleted instead if the endpoint is unknown, so that the ControlPlane provider regenerates it,
+// or if it was created for a cluster that is not managed by Cluster-API.
+func (r *ClusterConnectReconciler) deleteKubeconfig(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
+	log := log.FromContext(ctx)
+
+	kc := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cc.GetTunnelID() + "-kubeconfig",
+			Namespace: auth.SecretNamespace(),
+		},
+	}
+
+	if ref := cc.Spec.ClusterRef; ref != nil {
+		kc.Name = ref.Name + "-kubeconfig"
+		kc.Namespace = ref.Namespace
+
+		cluster := &clusterv1.Cluster{}
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, cluster)
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to get Cluster object %s/%s: %v", ref.Namespace, ref.Name, err)
+		}
+
+		// Nothing to clean up if the Cluster is gone or being deleted, as the kubeconfig Secret is owned by the Cluster.
+		if err != nil || !cluster.DeletionTimestamp.IsZero() {
+			return nil
+		}
+
+		endpoint := cluster.Spec.ControlPlaneEndpoint
+		if endpoint.IsValid() && endpoint.Host != r.controlPlaneEndpointHost {
+			return r.restoreKubeconfig(ctx, kc, cluster)
+		}
+	}
+
+	if err := r.Client.Delete(ctx, kc); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete kubeconfig Secret %s/%s: %v", kc.Namespace, kc.Name, err)
+	} else if err == nil {
+		log.Info("Deleted kubeconfig Secret", "secret", client.ObjectKeyFromObject(kc))
+	}
+	return nil
+}
+
+// restoreKubeconfig regenerates a given kubeconfig Secret with the control plane endpoint of a given Cluster.
+func (r *ClusterConnectReconciler) restoreKubeconfig(ctx context.Context, kc *corev1.Secret, cluster *clusterv1.Cluster) error {
+	log := log.FromContext(ctx)
+
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(kc), kc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to fetch kubeconfig Secret: %v", err)
+	}
+
+	patchHelper, err := patch.NewHelper(kc, r.Client)
+	if err != nil {
+		return fmt.Errorf("failed to create patch helper for kubeconfig Secret: %v", err)
+	}
+
+	server := fmt.Sprintf("https://%s", cluster.Spec.ControlPlaneEndpoint.String())
+	data, err := kubeutil.GenerateKubeconfig(ctx, r.Client, cluster.Name, cluster.Namespace, server)
+	if err != nil || data == nil {
+		return fmt.Errorf("failed to generate kubeconfig: %v", err)
+	}
+	if kc.Data == nil {
+		kc.Data = map[string][]byte{}
+	}
+	kc.Data[kubeutil.KubeconfigDataName] = data
+
+	if err := patchHelper.Patch(ctx, kc); err != nil {
+		return fmt.Errorf("failed to patch kubeconfig Secret %s/%s: %v", kc.Namespace, kc.Name, err)
+	}
+
+	log.Info("Restored kubeconfig Secret", "secret", client.ObjectKeyFromObject(kc), "server", server)
+	return nil
+}
+
 //nolint:unparam
 func (r *ClusterConnectReconciler) reconcile(ctx context.Context, cc *v1alpha1.ClusterConnect) (ctrl.Result, error) {
 	_ = log.FromContext(ctx)
@@ -324,30 +714,68 @@ func (r *ClusterConnectReconciler) reconcile(ctx context.Context, cc *v1alpha1.C
 		}
 	}
 
-	phases := []func(context.Context, *v1alpha1.ClusterConnect) error{
-		r.reconcileAuthToken,
-		r.reconcileConnectAgentManifest,
-		r.reconcileConnectionProbe,
-		r.reconcileControlPlaneEndpoint,
-		r.reconcileClusterSpec,
+	phases := []reconcilePhase{
+		{"auth_token", r.reconcileAuthToken},
+		{"agent_manifest", r.reconcileConnectAgentManifest},
+		{"connection_probe", r.reconcileConnectionProbe},
+		{"control_plane_endpoint", r.reconcileControlPlaneEndpoint},
+		{"cluster_spec", r.reconcileClusterSpec},
 	}
 
 	// Only add reconcileTopology for topology mode clusters
 	if !isLegacyMode {
-		phases = append(phases, r.reconcileTopology)
+		phases = append(phases, reconcilePhase{"topology", r.reconcileTopology})
 	}
 
-	phases = append(phases, r.reconcileKubeconfig)
+	phases = append(phases, reconcilePhase{"kubeconfig", r.reconcileKubeconfig})
 
 	errs := []error{}
 	for _, phase := range phases {
-		if err := phase(ctx, cc); err != nil {
+		if err := phase.run(ctx, cc); err != nil {
 			errs = append(errs, err)
 			break
 		}
 	}
+	if len(errs) > 0 {
+		return ctrl.Result{}, kerrors.NewAggregate(errs)
+	}
+
+	// Come back to rotate the agent token ahead of its expiration, and to re-evaluate the connection probe
+	// once it is due to time out.
+	var requeueAt time.Time
+	if rotateAt, ok := r.agentTokenRotationTime(cc); ok {
+		requeueAt = rotateAt
+	}
+	if checkAt, ok := r.connectionProbeCheckTime(cc); ok && (requeueAt.IsZero() || checkAt.Before(requeueAt)) {
+		requeueAt = checkAt
+	}
+	// Come back to correct the kubeconfig Secret once the previous correction is old enough.
+	if v1beta2conditions.GetReason(cc, v1alpha1.KubeconfigReadyCondition) == v1alpha1.KubeconfigDriftedReason {
+		if correctAt, ok := kubeconfigCorrectionTime(cc); ok && (requeueAt.IsZero() || correctAt.Before(requeueAt)) {
+			requeueAt = correctAt
+		}
+	}
+	if !requeueAt.IsZero() {
+		return ctrl.Result{RequeueAfter: max(time.Until(requeueAt), time.Second)}, nil
+	}
+	return ctrl.Result{}, nil
+}
 
-	return ctrl.Result{}, kerrors.NewAggregate(errs)
+// reconcilePhase is a named phase of the normal reconcile logic.
+type reconcilePhase struct {
+	name      string
+	reconcile func(context.Context, *v1alpha1.ClusterConnect) error
+}
+
+// run runs the phase and records its duration and failure in the controller metrics.
+func (p reconcilePhase) run(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
+	start := time.Now()
+	err := p.reconcile(ctx, cc)
+	metrics.ReconcilePhaseDuration.WithLabelValues(p.name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.ReconcilePhaseErrors.WithLabelValues(p.name).Inc()
+	}
+	return err
 }
 
 func (r *ClusterConnectReconciler) reconcileAuthToken(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
@@ -363,21 +791,79 @@ func (r *ClusterConnectReconciler) reconcileAuthToken(ctx context.Context, cc *v
 		return fmt.Errorf("failed to get token: %v", err)
 	}
 
-	// Return early, if the token already exists.
-	if exist {
-		setAuthTokenReadyConditionTrue(cc)
-		return nil
+	// Token doesn't exist. Create a new one.
+	if !exist {
+		if err := r.tokenManager.CreateAndStoreToken(ctx, tunnelId, cc); err != nil {
+			msg := "failed to create token"
+			setAuthTokenReadyConditionFalse(cc, msg)
+			return fmt.Errorf("%s: %v", msg, err)
+		}
 	}
 
-	// Token doesn't exist. Create a new one.
-	if err := r.tokenManager.CreateAndStoreToken(ctx, tunnelId, cc); err != nil {
-		msg := "failed to create token"
-		setAuthTokenReadyConditionFalse(cc, msg)
-		return fmt.Errorf("%s: %v", msg, err)
-	} else {
-		setAuthTokenReadyConditionTrue(cc)
-		return nil
+	token, err := r.tokenManager.GetToken(ctx, tunnelId)
+	if err != nil || token == nil {
+		return fmt.Errorf("failed to retrieve token: %v", err)
+	}
+
+	// Rotate the token if it is forced, or about to expire.
+	// The new token is propagated by the following phases through the connect-agent manifest.
+	if reason := r.agentTokenRotationReason(cc, token); reason != "" {
+		// Revoke the previous token immediately if the rotation is forced, e.g. after a leak.
+		gracePeriod := r.AgentTokenGracePeriod
+		if hasAnnotation(cc, v1alpha1.RotateAgentTokenAnnotation) {
+			gracePeriod = 0
+		}
+
+		if err := r.tokenManager.RefreshToken(ctx, tunnelId, gracePeriod); err != nil {
+			msg := "failed to rotate token"
+			setAuthTokenReadyConditionFalse(cc, msg)
+			return fmt.Errorf("%s: %v", msg, err)
+		}
+		delete(cc.Annotations, v1alpha1.RotateAgentTokenAnnotation)
+		r.recorder.Eventf(cc, nil, corev1.EventTypeNormal, "AgentTokenRotated", "RotateToken", "Agent token rotated: %s", reason)
+
+		if token, err = r.tokenManager.GetToken(ctx, tunnelId); err != nil || token == nil {
+			return fmt.Errorf("failed to retrieve token: %v", err)
+		}
+	}
+
+	cc.Status.AgentTokenExpiresAt = nil
+	if !token.ExpiresAt.IsZero() {
+		expiresAt := metav1.NewTime(token.ExpiresAt)
+		cc.Status.AgentTokenExpiresAt = &expiresAt
+	}
+
+	setAuthTokenReadyConditionTrue(cc)
+	return nil
+}
+
+// agentTokenRotationReason returns why a given token needs to be rotated, or an empty string if it doesn't.
+func (r *ClusterConnectReconciler) agentTokenRotationReason(cc *v1alpha1.ClusterConnect, token *auth.Token) string {
+	switch {
+	case hasAnnotation(cc, v1alpha1.RotateAgentTokenAnnotation):
+		return "rotation requested by annotation"
+	case r.AgentTokenTTL <= 0:
+		return ""
+	case token.ExpiresAt.IsZero():
+		return "token without expiration"
+	case !time.Now().Before(token.ExpiresAt.Add(-r.AgentTokenTTL / 5)):
+		return fmt.Sprintf("token expires at %s", token.ExpiresAt.Format(time.RFC3339))
+	default:
+		return ""
+	}
+}
+
+// agentTokenRotationTime returns the time the agent token of a given ClusterConnect is due for rotation.
+func (r *ClusterConnectReconciler) agentTokenRotationTime(cc *v1alpha1.ClusterConnect) (time.Time, bool) {
+	if r.AgentTokenTTL <= 0 || cc.Status.AgentTokenExpiresAt == nil {
+		return time.Time{}, false
 	}
+	return cc.Status.AgentTokenExpiresAt.Add(-r.AgentTokenTTL / 5), true
+}
+
+func hasAnnotation(obj client.Object, key string) bool {
+	_, ok := obj.GetAnnotations()[key]
+	return ok
 }
 
 func (r *ClusterConnectReconciler) reconcileConnectAgentManifest(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
@@ -390,7 +876,7 @@ func (r *ClusterConnectReconciler) reconcileConnectAgentManifest(ctx context.Con
 		return fmt.Errorf("failed to retrieve token: %v", err)
 	}
 
-	manifest, err := agentconfig.GenerateAgentConfig(tunnelId, token.Value)
+	manifest, err := agentconfig.GenerateAgentConfig(tunnelId, token.Value, cc.Spec.Agent)
 	if err != nil {
 		msg := "failed to generate agent manifest"
 		setAgentManifestGeneratedConditionFalse(cc, msg)
@@ -406,12 +892,32 @@ func (r *ClusterConnectReconciler) reconcileConnectAgentManifest(ctx context.Con
 func (r *ClusterConnectReconciler) reconcileControlPlaneEndpoint(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
 	// Cluster API doesn't allow sub-path in the ControlPlaneEndpoint API URL.
 	// Value here is to just pass the contract and won't be used.
-	// TODO: Create another field to provide the control plane endpoint that actually works.
 	cc.Status.ControlPlaneEndpoint = clusterv1.APIEndpoint{
 		Host: r.controlPlaneEndpointHost,
 		Port: r.controlPlaneEndpointPort,
 	}
 
+	// Publish the URLs that actually work, with the sub-path of the tunnel.
+	endpoint := &v1alpha1.GatewayEndpoint{
+		InternalURL: r.getControlPlaneEndpointUrl(cc),
+	}
+	if r.gatewayExternalURL != nil {
+		endpoint.ExternalURL = r.gatewayExternalURL.JoinPath("kubernetes", cc.GetTunnelID()).String()
+
+		// The external gateway is exposed through the orchestrator ingress, whose certificate is issued
+		// by the private CA, unless another CA is given.
+		endpoint.CABundle = r.gatewayCABundle
+		if len(endpoint.CABundle) == 0 && os.Getenv(privateCAEnabledEnv) == "true" {
+			caCrt, err := kubeutil.GetAPIServerCA(ctx, r.Client)
+			if err != nil {
+				setControlPlaneEndpointSetConditionFalse(cc, "Failed to get the gateway CA bundle")
+				return fmt.Errorf("failed to get APIServer CA: %v", err)
+			}
+			endpoint.CABundle = caCrt
+		}
+	}
+	cc.Status.GatewayEndpoint = endpoint
+
 	setControlPlaneEndpointSetConditionTrue(cc)
 	return nil
 }
@@ -443,10 +949,12 @@ func (r *ClusterConnectReconciler) reconcileClusterSpec(ctx context.Context, cc
 	}
 
 	// Prepare the agent configuration.
+	controlPlaneProvider := r.controlPlaneProvider(cluster)
 	agentConfig := &ConnectAgentConfig{
-		Path:    r.providerManager.StaticPodManifestPath(cluster.Spec.ControlPlaneRef.Kind),
-		Owner:   "root:root",
-		Content: cc.Status.AgentManifest,
+		Path:        controlPlaneProvider.StaticPodManifestPath,
+		Owner:       controlPlaneProvider.FileOwner,
+		Permissions: controlPlaneProvider.FilePermissions,
+		Content:     cc.Status.AgentManifest,
 	}
 
 	agentConfigJson, err := json.Marshal(agentConfig)
@@ -473,7 +981,7 @@ func (r *ClusterConnectReconciler) reconcileClusterSpec(ctx context.Context, cc
 			}
 
 			// Update the variable if it doesn't match the desired configuration.
-			if existingConfig.Path != agentConfig.Path || existingConfig.Content != agentConfig.Content || existingConfig.Owner != agentConfig.Owner {
+			if existingConfig != *agentConfig {
 				cluster.Spec.Topology.Variables[i].Value = v1.JSON{Raw: agentConfigJson}
 				variableUpdated = true
 			} else {
@@ -551,10 +1059,10 @@ func (r *ClusterConnectReconciler) reconcileTopology(ctx context.Context, cc *v1
 func (r *ClusterConnectReconciler) reconcileKubeconfig(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
 	log := log.FromContext(ctx)
 
-	// Return early, if the ClusterConnect doesn't have associated Cluster-API resources.
-	// TODO: support this feature for non-CAPI managed cluster as well.
+	// Generate the kubeconfig Secret from the referenced Secrets, if the ClusterConnect doesn't have
+	// associated Cluster-API resources.
 	if cc.Spec.ClusterRef == nil {
-		return nil
+		return r.reconcileManagedKubeconfig(ctx, cc)
 	}
 
 	// Get cluster name and namespace from ClusterRef.
@@ -562,28 +1070,24 @@ func (r *ClusterConnectReconciler) reconcileKubeconfig(ctx context.Context, cc *
 	clusterName := cc.Spec.ClusterRef.Name
 
 	// Set the labels with kubeconfig Secret name and namespce for use in secretToClusteConnectMapper.
-	labels := cc.GetLabels()
-	if labels == nil {
-		labels = make(map[string]string)
+	setKubeconfigLabels(cc, clusterName+"-kubeconfig", clusterNamespace)
+
+	// Watch the kubeconfig Secret objects so that the controller is notified when the Secret is created,
+	// and when Cluster-API regenerates it, e.g. on certificate rotation, which overwrites the server URL.
+	kc := &corev1.Secret{}
+	if err := r.externalTracker.Watch(log, kc, handler.EnqueueRequestsFromMapFunc(r.secretToClusterConnectMapper),
+		kubeconfigSecretPredicate); err != nil {
+		return fmt.Errorf("failed to add watch on kubeconfig secret: %v", err)
 	}
-	labels["cluster.x-k8s.io/kubeconfig-name"] = clusterName + "-kubeconfig"
-	labels["cluster.x-k8s.io/kubeconfig-namespace"] = clusterNamespace
-	cc.SetLabels(labels)
 
 	// Fetch kubeconfig Secret.
-	kc := &corev1.Secret{}
 	err := r.Client.Get(ctx, client.ObjectKey{
 		Namespace: clusterNamespace,
 		Name:      clusterName + "-kubeconfig",
 	}, kc)
 
 	// Return early, if kubeconfig Secret object doesn't exist yet.
-	// Setup dynamic watch to the kubeconfig Secret object so that the controller can be notificed.
 	if apierrors.IsNotFound(err) {
-		if err := r.externalTracker.Watch(log, kc, handler.EnqueueRequestsFromMapFunc(r.secretToClusterConnectMapper),
-			createOnlyPredicate); err != nil {
-			return fmt.Errorf("failed to add watch on kubeconfig secret: %v", err)
-		}
 		return nil
 	}
 
@@ -591,6 +1095,31 @@ func (r *ClusterConnectReconciler) reconcileKubeconfig(ctx context.Context, cc *
 		return fmt.Errorf("failed to fetch kubeconfig Secret: %v", err)
 	}
 
+	// Return early, if the kubeconfig Secret already points to the gateway endpoint.
+	drift, err := r.kubeconfigDrift(ctx, cc, kc)
+	if err != nil {
+		return err
+	}
+	if drift == "" {
+		if !v1beta2conditions.IsTrue(cc, v1alpha1.KubeconfigReadyCondition) {
+			setKubeconfigReadyConditionTrue(cc)
+		}
+		return nil
+	}
+
+	// The kubeconfig Secret was overwritten after it was updated, e.g. by Cluster-API, so it needs a correction.
+	// Delay the correction if the previous one is recent, so as not to fight over the Secret in a hot loop.
+	correction := v1beta2conditions.IsTrue(cc, v1alpha1.KubeconfigReadyCondition) ||
+		v1beta2conditions.GetReason(cc, v1alpha1.KubeconfigReadyCondition) == v1alpha1.KubeconfigDriftedReason
+	if correction {
+		if correctAt, ok := kubeconfigCorrectionTime(cc); ok && time.Now().Before(correctAt) {
+			log.Info("Delaying the correction of the kubeconfig Secret", "drift", drift, "correctAt", correctAt)
+			setKubeconfigDriftedCondition(cc, fmt.Sprintf("Kubeconfig Secret drifted from the gateway endpoint: %s. It will be corrected at %s",
+				drift, correctAt.Format(time.RFC3339)))
+			return nil
+		}
+	}
+
 	// Kubeconfig Secret exists, update the server URL.
 	patchHelper, err := patch.NewHelper(kc, r.Client)
 	if err != nil {
@@ -601,13 +1130,168 @@ func (r *ClusterConnectReconciler) reconcileKubeconfig(ctx context.Context, cc *
 	// Better approach would be create a kubeconfig secret along with required certificates before the ControlPlane creates.
 	// But that requires cluster-connect-gateway to manages certificates which is not implemented.
 	// So just update the existing kubeconfig Secret now.
-	data, err := kubeutil.GenerateKubeconfig(ctx, r.Client, clusterName, clusterNamespace, getControlPlaneEndpointUrl(cc))
+	data, err := kubeutil.GenerateKubeconfig(ctx, r.Client, clusterName, clusterNamespace, r.getControlPlaneEndpointUrl(cc))
 	if err != nil || data == nil {
 		return fmt.Errorf("failed to generate kubeconfig: %v", err)
 	}
 
-	kc.Data = map[string][]byte{
-		kubeutil.KubeconfigDataName: data,
+	if kc.Data, err = r.kubeconfigSecretData(ctx, data); err != nil {
+		return err
+	}
+
+	// Patch the updates after each reconciliation.
+	patchOpts := []patch.Option{patch.WithStatusObservedGeneration{}}
+	if err := patchHelper.Patch(ctx, kc, patchOpts...); err != nil {
+		return fmt.Errorf("failed to patch ControlPlane object: %v", err)
+	}
+
+	if !correction {
+		setKubeconfigReadyConditionTrue(cc)
+		return nil
+	}
+
+	log.Info("Corrected kubeconfig Secret", "secret", client.ObjectKeyFromObject(kc), "drift", drift)
+	r.recorder.Eventf(cc, kc, corev1.EventTypeNormal, "KubeconfigCorrected", "CorrectKubeconfig",
+		"Kubeconfig Secret drifted from the gateway endpoint and was corrected: %s", drift)
+	cc.SetAnnotations(setAnnotation(cc.GetAnnotations(), v1alpha1.KubeconfigCorrectedAtAnnotation, time.Now().UTC().Format(time.RFC3339)))
+	setKubeconfigCorrectedCondition(cc, fmt.Sprintf("Kubeconfig Secret was corrected: %s", drift))
+	return nil
+}
+
+// kubeconfigDrift reports how a given CAPI kubeconfig Secret drifted from the gateway endpoint of a given
+// ClusterConnect. It returns an empty string if the Secret points to the gateway endpoint.
+func (r *ClusterConnectReconciler) kubeconfigDrift(ctx context.Context, cc *v1alpha1.ClusterConnect, kc *corev1.Secret) (string, error) {
+	drift, err := kubeutil.KubeconfigDrift(ctx, r.Client, cc.Spec.ClusterRef.Name, cc.Spec.ClusterRef.Namespace,
+		kc.Data[kubeutil.KubeconfigDataName], r.getControlPlaneEndpointUrl(cc))
+	if err != nil {
+		return "", fmt.Errorf("failed to check kubeconfig: %v", err)
+	}
+	if drift != "" || os.Getenv(privateCAEnabledEnv) != "true" {
+		return drift, nil
+	}
+
+	caCrt, err := kubeutil.GetAPIServerCA(ctx, r.Client)
+	if err != nil {
+		return "", fmt.Errorf("failed to get APIServer CA: %v", err)
+	}
+	if !bytes.Equal(kc.Data[kubeutil.ApiServerCA], caCrt) {
+		return "private CA doesn't match the APIServer CA", nil
+	}
+	return "", nil
+}
+
+// kubeconfigCorrectionTime returns the earliest time the kubeconfig Secret of a given ClusterConnect object
+// can be corrected again, if it was corrected before.
+func kubeconfigCorrectionTime(cc *v1alpha1.ClusterConnect) (time.Time, bool) {
+	correctedAt, err := time.Parse(time.RFC3339, cc.GetAnnotations()[v1alpha1.KubeconfigCorrectedAtAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return correctedAt.Add(kubeconfigCorrectionInterval), true
+}
+
+// setAnnotation returns given annotations with a given key set to a given value.
+func setAnnotation(annotations map[string]string, key, value string) map[string]string {
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	return annotations
+}
+
+// reconcileManagedKubeconfig creates the kubeconfig Secret for a cluster that is not managed by Cluster-API.
+// The kubeconfig is taken from KubeconfigRef, or generated from the certificates in ServerCertRef and ClientCertRef.
+// The Secret is stored next to the token Secret and owned by the ClusterConnect. It is labeled with the cluster name
+// like the Cluster-API kubeconfig Secrets, so that the gateway watches it as well.
+func (r *ClusterConnectReconciler) reconcileManagedKubeconfig(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
+	var data []byte
+	var err error
+	switch {
+	case cc.Spec.KubeconfigRef != nil:
+		data, err = r.kubeconfigFromRef(ctx, cc)
+	case cc.Spec.ServerCertRef != nil && cc.Spec.ClientCertRef != nil:
+		data, err = r.kubeconfigFromCertRefs(ctx, cc)
+	default:
+		// Return early, if there is nothing to generate the kubeconfig from.
+		return nil
+	}
+	if err != nil {
+		setKubeconfigReadyConditionFalse(cc, err.Error())
+		return err
+	}
+
+	kc := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cc.GetTunnelID() + "-kubeconfig",
+			Namespace: auth.SecretNamespace(),
+		},
+	}
+
+	secretData, err := r.kubeconfigSecretData(ctx, data)
+	if err != nil {
+		setKubeconfigReadyConditionFalse(cc, err.Error())
+		return err
+	}
+
+	if _, err := cutil.CreateOrUpdate(ctx, r.Client, kc, func() error {
+		if kc.Labels == nil {
+			kc.Labels = map[string]string{}
+		}
+		kc.Labels[clusterv1.ClusterNameLabel] = cc.Name
+		kc.Data = secretData
+		return cutil.SetOwnerReference(cc, kc, r.Scheme)
+	}); err != nil {
+		setKubeconfigReadyConditionFalse(cc, "Failed to update kubeconfig Secret")
+		return fmt.Errorf("failed to create or update kubeconfig Secret %s/%s: %v", kc.Namespace, kc.Name, err)
+	}
+
+	// Set the labels with kubeconfig Secret name and namespace for use in the gateway.
+	setKubeconfigLabels(cc, kc.Name, kc.Namespace)
+
+	setKubeconfigReadyConditionTrue(cc)
+	return nil
+}
+
+// kubeconfigFromRef returns the kubeconfig from KubeconfigRef with the server URL set to the gateway.
+func (r *ClusterConnectReconciler) kubeconfigFromRef(ctx context.Context, cc *v1alpha1.ClusterConnect) ([]byte, error) {
+	ref := cc.Spec.KubeconfigRef
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig Secret %s/%s: %v", ref.Namespace, ref.Name, err)
+	}
+
+	data, err := kubeutil.SetKubeconfigServer(secret.Data[kubeutil.KubeconfigDataName], r.getControlPlaneEndpointUrl(cc))
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig in Secret %s/%s: %v", ref.Namespace, ref.Name, err)
+	}
+	return data, nil
+}
+
+// kubeconfigFromCertRefs generates a kubeconfig from the certificates in ServerCertRef and ClientCertRef.
+func (r *ClusterConnectReconciler) kubeconfigFromCertRefs(ctx context.Context, cc *v1alpha1.ClusterConnect) ([]byte, error) {
+	serverCA := &corev1.Secret{}
+	serverRef := cc.Spec.ServerCertRef
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: serverRef.Namespace, Name: serverRef.Name}, serverCA); err != nil {
+		return nil, fmt.Errorf("failed to get server certificate Secret %s/%s: %v", serverRef.Namespace, serverRef.Name, err)
+	}
+
+	clientClusterCA := &corev1.Secret{}
+	clientRef := cc.Spec.ClientCertRef
+	if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clientRef.Namespace, Name: clientRef.Name}, clientClusterCA); err != nil {
+		return nil, fmt.Errorf("failed to get client certificate Secret %s/%s: %v", clientRef.Namespace, clientRef.Name, err)
+	}
+
+	data, err := kubeutil.GenerateKubeconfigFromSecrets(cc.GetTunnelID(), serverCA, clientClusterCA, r.getControlPlaneEndpointUrl(cc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate kubeconfig: %v", err)
+	}
+	return data, nil
+}
+
+// kubeconfigSecretData returns the data of the kubeconfig Secret for a given kubeconfig.
+func (r *ClusterConnectReconciler) kubeconfigSecretData(ctx context.Context, kubeconfig []byte) (map[string][]byte, error) {
+	data := map[string][]byte{
+		kubeutil.KubeconfigDataName: kubeconfig,
 	}
 
 	// Enabling private CA will set an orchestration self-signed certificate in the kubeConfig secret
@@ -616,19 +1300,23 @@ func (r *ClusterConnectReconciler) reconcileKubeconfig(ctx context.Context, cc *
 	if privateCaEnabled == "true" {
 		caCrt, err := kubeutil.GetAPIServerCA(ctx, r.Client)
 		if err != nil {
-			return fmt.Errorf("failed to get APIServer CA: %v", err)
+			return nil, fmt.Errorf("failed to get APIServer CA: %v", err)
 		}
-		kc.Data[kubeutil.ApiServerCA] = caCrt
+		data[kubeutil.ApiServerCA] = caCrt
 	}
 
-	// Patch the updates after each reconciliation.
-	patchOpts := []patch.Option{patch.WithStatusObservedGeneration{}}
-	if err := patchHelper.Patch(ctx, kc, patchOpts...); err != nil {
-		return fmt.Errorf("failed to patch ControlPlane object: %v", err)
-	}
+	return data, nil
+}
 
-	setKubeconfigReadyConditionTrue(cc)
-	return nil
+// setKubeconfigLabels sets the labels with the kubeconfig Secret name and namespace.
+func setKubeconfigLabels(cc *v1alpha1.ClusterConnect, name, namespace string) {
+	labels := cc.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels["cluster.x-k8s.io/kubeconfig-name"] = name
+	labels["cluster.x-k8s.io/kubeconfig-namespace"] = namespace
+	cc.SetLabels(labels)
 }
 
 func (r *ClusterConnectReconciler) reconcileLegacyMode(ctx context.Context, cc *v1alpha1.ClusterConnect, cluster *clusterv1.Cluster) error {
@@ -664,37 +1352,23 @@ func (r *ClusterConnectReconciler) reconcileLegacyMode(ctx context.Context, cc *
 	}
 
 	// Prepare the agent configuration file
+	controlPlaneProvider := r.controlPlaneProvider(cluster)
+	agentFilePath := legacyAgentFilePath(controlPlaneProvider)
 	agentFile := map[string]interface{}{
-		"path":    agentManifestPath,
-		"owner":   "root:root",
+		"path":    agentFilePath,
+		"owner":   controlPlaneProvider.FileOwner,
 		"content": cc.Status.AgentManifest,
 	}
-
-	// Get existing files from the correct path based on control plane kind
-	spec, exists := controlPlane.Object["spec"].(map[string]interface{})
-	if !exists {
-		spec = make(map[string]interface{})
-		controlPlane.Object["spec"] = spec
+	if controlPlaneProvider.FilePermissions != "" {
+		agentFile["permissions"] = controlPlaneProvider.FilePermissions
 	}
 
+	// Get existing files from the path defined by the control plane provider
 	var files []interface{}
-	var filesPath []string
-
-	// Determine the correct path for files based on the control plane kind
-	switch controlPlane.GetKind() {
-	case "KThreesControlPlane":
-		// For KThreesControlPlane, files are at spec.kthreesConfigSpec.files
-		filesPath = []string{"kthreesConfigSpec", "files"}
-	case "RKE2ControlPlane":
-		// For RKE2ControlPlane, files are at spec.files
-		filesPath = []string{"files"}
-	default:
-		// Default to spec.files for other providers
-		filesPath = []string{"files"}
-	}
+	filesPath := controlPlaneProvider.FilesFields()
 
 	// Navigate to the correct nested path
-	current := spec
+	current := controlPlane.Object
 	for _, pathSegment := range filesPath[:len(filesPath)-1] {
 		if next, exists := current[pathSegment].(map[string]interface{}); exists {
 			current = next
@@ -717,9 +1391,9 @@ func (r *ClusterConnectReconciler) reconcileLegacyMode(ctx context.Context, cc *
 	agentFileExists := false
 	for i, file := range files {
 		if fileMap, ok := file.(map[string]interface{}); ok {
-			if path, ok := fileMap["path"].(string); ok && path == agentManifestPath {
-				// Update existing file if content differs
-				if content, ok := fileMap["content"].(string); !ok || content != cc.Status.AgentManifest {
+			if isAgentManifestFile(file, agentFilePath) {
+				// Update existing file if path, content, owner or permissions differ
+				if !reflect.DeepEqual(fileMap, agentFile) {
 					files[i] = agentFile
 					log.Info("Updated connect-agent.yaml file in ControlPlane", "controlPlane", controlPlaneKey)
 				} else {
@@ -755,8 +1429,37 @@ func (r *ClusterConnectReconciler) reconcileLegacyMode(ctx context.Context, cc *
 	return nil
 }
 
+// controlPlaneProvider returns the definition of the provider of the ControlPlane of a given cluster.
+// Unknown providers get a definition with the default files path and no static pod manifest path.
+func (r *ClusterConnectReconciler) controlPlaneProvider(cluster *clusterv1.Cluster) provider.Provider {
+	controlPlaneRef := cluster.Spec.ControlPlaneRef
+	if p, ok := r.providerManager.Provider(controlPlaneRef.APIGroup, controlPlaneRef.Kind); ok {
+		return p
+	}
+	return provider.Unknown(controlPlaneRef.APIGroup, controlPlaneRef.Kind)
+}
+
+// legacyAgentFilePath returns the path of the connect-agent file injected into the ControlPlane of a given provider
+// in legacy mode. The providers keep the former relative path, unless they define another one, e.g. kubeadm.
+func legacyAgentFilePath(p provider.Provider) string {
+	if p.LegacyManifestPath != "" {
+		return p.LegacyManifestPath
+	}
+	return agentManifestPath
+}
+
+// isAgentManifestFile reports whether a file of a ControlPlane object is the connect-agent file.
+// Files injected with the former relative path are matched as well, so that they are replaced or removed.
+func isAgentManifestFile(file interface{}, path string) bool {
+	fileMap, ok := file.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	return fileMap["path"] == path || fileMap["path"] == agentManifestPath
+}
+
 func (r *ClusterConnectReconciler) reconcileConnectionProbe(ctx context.Context, cc *v1alpha1.ClusterConnect) error {
-	log.FromContext(ctx)
+	log := log.FromContext(ctx)
 	// Initialize ConnectionProbe if not already set.
 	if cc.Status.ConnectionProbe == (v1alpha1.ConnectionProbeState{}) {
 		cc.Status.ConnectionProbe = v1alpha1.ConnectionProbeState{
@@ -765,24 +1468,73 @@ func (r *ClusterConnectReconciler) reconcileConnectionProbe(ctx context.Context,
 		}
 	}
 
-	if cc.Status.ConnectionProbe.LastProbeSuccessTimestamp.IsZero() {
-		// initConditions will keep ConnectionProbeCondition Unknown
-		return nil
+	// The connection probe is evaluated against the current time, as the connection gateway stops
+	// probing the tunnels it no longer holds a session for.
+	now := time.Now()
+	probe := cc.Status.ConnectionProbe
+	previousStatus := metav1.ConditionUnknown
+	if previous := v1beta2conditions.Get(cc, v1alpha1.ConnectionProbeCondition); previous != nil {
+		previousStatus = previous.Status
 	}
 
-	timeDiff := cc.Status.ConnectionProbe.LastProbeTimestamp.Time.Sub(cc.Status.ConnectionProbe.LastProbeSuccessTimestamp.Time)
-	if timeDiff > clusterConnectConnectionProbeTimeout {
+	if probe.LastProbeSuccessTimestamp.IsZero() {
+		// Give the connect-agent time to be deployed before reporting a failure.
+		if elapsed := now.Sub(cc.CreationTimestamp.Time); elapsed > r.connectionProbeGracePeriod() {
+			setConnectionProbeConditionFalse(cc, fmt.Sprintf("Remote connection probe never succeeded. Time since creation: %s",
+				elapsed.Round(time.Second).String()))
+		}
+		// initConditions will keep ConnectionProbeCondition Unknown otherwise
+	} else if timeDiff := now.Sub(probe.LastProbeSuccessTimestamp.Time); timeDiff > clusterConnectConnectionProbeTimeout {
+		lastProbe := "never"
+		if !probe.LastProbeTimestamp.IsZero() {
+			lastProbe = probe.LastProbeTimestamp.Time.Format(time.RFC3339)
+		}
 		msg := fmt.Sprintf("Remote connection probe failed. Time since last successful probe: %s. Last probe: %s, Last successful probe: %s",
-			timeDiff.String(),
-			cc.Status.ConnectionProbe.LastProbeTimestamp.Time.Format(time.RFC3339),
-			cc.Status.ConnectionProbe.LastProbeSuccessTimestamp.Time.Format(time.RFC3339))
+			timeDiff.Round(time.Second).String(),
+			lastProbe,
+			probe.LastProbeSuccessTimestamp.Time.Format(time.RFC3339))
 		setConnectionProbeConditionFalse(cc, msg)
 	} else {
 		setConnectionProbeConditionTrue(cc)
 	}
+
+	// Record an event whenever the connection state flips.
+	current := v1beta2conditions.Get(cc, v1alpha1.ConnectionProbeCondition)
+	if current == nil || current.Status == previousStatus {
+		return nil
+	}
+	switch current.Status {
+	case metav1.ConditionFalse:
+		log.Info("Connection to connect-agent lost", "message", current.Message)
+		r.recorder.Eventf(cc, nil, corev1.EventTypeWarning, "ConnectionProbeFailed", "ConnectionProbe", "%s", current.Message)
+	case metav1.ConditionTrue:
+		log.Info("Connection to connect-agent established")
+		r.recorder.Eventf(cc, nil, corev1.EventTypeNormal, "ConnectionProbeSucceeded", "ConnectionProbe", "Remote connection probe succeeded")
+	}
 	return nil
 }
 
+// connectionProbeGracePeriod returns the period a connection probe that never succeeded is not reported as failed.
+func (r *ClusterConnectReconciler) connectionProbeGracePeriod() time.Duration {
+	if r.ConnectionProbeGracePeriod > 0 {
+		return r.ConnectionProbeGracePeriod
+	}
+	return clusterConnectConnectionProbeTimeout
+}
+
+// connectionProbeCheckTime returns the time the ConnectionProbe condition of a given ClusterConnect object
+// is due to turn False, if no successful probe is recorded meanwhile.
+func (r *ClusterConnectReconciler) connectionProbeCheckTime(cc *v1alpha1.ClusterConnect) (time.Time, bool) {
+	if v1beta2conditions.IsFalse(cc, v1alpha1.ConnectionProbeCondition) {
+		// A successful probe updates the status, which triggers a reconcile.
+		return time.Time{}, false
+	}
+	if lastSuccess := cc.Status.ConnectionProbe.LastProbeSuccessTimestamp; !lastSuccess.IsZero() {
+		return lastSuccess.Add(clusterConnectConnectionProbeTimeout), true
+	}
+	return cc.CreationTimestamp.Add(r.connectionProbeGracePeriod()), true
+}
+
 func (r *ClusterConnectReconciler) clusterToClusterConnectMapper(ctx context.Context, obj client.Object) []ctrl.Request {
 	var ccList v1alpha1.ClusterConnectList
 
@@ -830,11 +1582,27 @@ func (r *ClusterConnectReconciler) secretToClusterConnectMapper(ctx context.Cont
 	}
 }
 
-func getControlPlaneEndpointUrl(cc *v1alpha1.ClusterConnect) string {
-	// TODO: Get scheme separately.
-	return fmt.Sprintf("http://%s:%d/kubernetes/%s",
-		cc.Status.ControlPlaneEndpoint.Host,
-		cc.Status.ControlPlaneEndpoint.Port,
-		cc.GetTunnelID(),
-	)
+// getControlPlaneEndpointUrl returns the internal URL of the kubeapi-server through the connection gateway.
+func (r *ClusterConnectReconciler) getControlPlaneEndpointUrl(cc *v1alpha1.ClusterConnect) string {
+	return r.gatewayInternalURL.JoinPath("kubernetes", cc.GetTunnelID()).String()
+}
+
+// toHTTPURL parses a given gateway URL and converts the websocket scheme used by the connect-agent to HTTP.
+func toHTTPURL(rawURL string) (*url.URL, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parsedURL.Scheme {
+	case "ws":
+		parsedURL.Scheme = "http"
+	case "wss":
+		parsedURL.Scheme = "https"
+	case "http", "https":
+		// Do nothing
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", parsedURL.Scheme)
+	}
+	return parsedURL, nil
 }
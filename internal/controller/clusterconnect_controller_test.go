@@ -4,6 +4,7 @@
 package controller
 
 import (
+	"encoding/json"
 	"time"
 
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -11,13 +12,19 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/clientcmd"
 	clusterv1 "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/certutil"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/kubeutil"
 )
 
 var _ = Describe("ClusterConnect Controller", Ordered, func() {
@@ -88,9 +95,15 @@ var _ = Describe("ClusterConnect Controller", Ordered, func() {
 					cc.Status.ControlPlaneEndpoint.Port == 8080
 			}, timeout, interval).Should(BeTrue())
 
+			// Ensure the gateway URLs of the tunnel are set.
+			Expect(cc.Status.GatewayEndpoint).NotTo(BeNil())
+			Expect(cc.Status.GatewayEndpoint.InternalURL).To(Equal("http://connect-gateway.default.svc:8080/kubernetes/" + testName))
+			Expect(cc.Status.GatewayEndpoint.ExternalURL).To(Equal("https://connect-gateway.fake.com:443/kubernetes/" + testName))
+
 			// Ensure there are four conditions and status.ready is true.
 			Expect(cc.Status.Conditions).To(HaveLen(4))
 			Expect(cc.Status.Ready).To(BeTrue())
+			Expect(cc.Status.FirstReadyTimestamp).NotTo(BeNil())
 		})
 	})
 
@@ -210,7 +223,8 @@ var _ = Describe("ClusterConnect Controller", Ordered, func() {
 			By("Cleanup the specific resource instance ClusterConnect and ControlPlane")
 			Expect(k8sClient.Delete(ctx, cc)).To(Succeed())
 			Expect(k8sClient.Delete(ctx, cl)).To(Succeed())
-			Expect(k8sClient.Delete(ctx, kc)).To(Succeed())
+			// The kubeconfig Secret may already be deleted by the finalizer of the ClusterConnect.
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, kc))).To(Succeed())
 			Expect(k8sClient.Delete(ctx, ca)).To(Succeed())
 			Expect(k8sClient.Delete(ctx, cca)).To(Succeed())
 		})
@@ -263,6 +277,523 @@ var _ = Describe("ClusterConnect Controller", Ordered, func() {
 				return err == nil &&
 					kubeconfig.Clusters[testName].Server == "http://connect-gateway.default.svc:8080/kubernetes/test2"
 			}, timeout, interval).Should(BeTrue())
+
+			// Overwrite the server URL of the kubeconfig Secret, as Cluster-API does when it regenerates the Secret.
+			overwritten, err := kubeutil.SetKubeconfigServer(kc.Data["value"], "https://10.0.0.1:6443")
+			Expect(err).NotTo(HaveOccurred())
+			kc.Data["value"] = overwritten
+			Expect(k8sClient.Update(ctx, kc)).To(Succeed())
+
+			// Ensure the kubeconfig Secret is corrected and the correction is recorded.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testKubeconfig, kc)
+				Expect(err).NotTo(HaveOccurred())
+				kubeconfig, err := clientcmd.Load(kc.Data["value"])
+				return err == nil &&
+					kubeconfig.Clusters[testName].Server == "http://connect-gateway.default.svc:8080/kubernetes/test2"
+			}, timeout, interval).Should(BeTrue())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				condition := meta.FindStatusCondition(cc.Status.Conditions, v1alpha1.KubeconfigReadyCondition)
+				return err == nil && condition != nil && condition.Reason == v1alpha1.KubeconfigCorrectedReason &&
+					cc.Annotations[v1alpha1.KubeconfigCorrectedAtAnnotation] != ""
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When suspending a ClusterConnect resource", func() {
+		var (
+			testName           = "test3"
+			testClusterConnect = types.NamespacedName{Name: testName}
+		)
+
+		BeforeEach(func() {
+			By("creating the suspended custom resource for the Kind ClusterConnect")
+			resource := &v1alpha1.ClusterConnect{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testName,
+				},
+				Spec: v1alpha1.ClusterConnectSpec{
+					Suspended: true,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &v1alpha1.ClusterConnect{}
+			err := k8sClient.Get(ctx, testClusterConnect, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance ClusterConnect")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should surface the suspension without affecting readiness", func() {
+			// Ensure Suspended condition is true and status.ready is true.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && cc.Status.Ready &&
+					meta.IsStatusConditionTrue(cc.Status.Conditions, v1alpha1.SuspendedCondition)
+			}, timeout, interval).Should(BeTrue())
+
+			// Resume the tunnel.
+			cc.Spec.Suspended = false
+			Expect(k8sClient.Update(ctx, cc)).To(Succeed())
+
+			// Ensure Suspended condition is false.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && cc.Status.Ready &&
+					meta.IsStatusConditionFalse(cc.Status.Conditions, v1alpha1.SuspendedCondition)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When the connection probe of a ClusterConnect resource times out", func() {
+		var (
+			testName           = "test-probe"
+			testClusterConnect = types.NamespacedName{Name: testName}
+		)
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind ClusterConnect")
+			resource := &v1alpha1.ClusterConnect{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testName,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &v1alpha1.ClusterConnect{}
+			err := k8sClient.Get(ctx, testClusterConnect, resource)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Cleanup the specific resource instance ClusterConnect")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should turn the ConnectionProbe condition False without another trigger", func() {
+			// Ensure a probe that never succeeded is reported as failed after the grace period.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && meta.IsStatusConditionFalse(cc.Status.Conditions, v1alpha1.ConnectionProbeCondition)
+			}, timeout, interval).Should(BeTrue())
+
+			// Record a successful probe, as the connection gateway does.
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, testClusterConnect, cc); err != nil {
+					return err
+				}
+				now := metav1.Now()
+				cc.Status.ConnectionProbe.LastProbeTimestamp = now
+				cc.Status.ConnectionProbe.LastProbeSuccessTimestamp = now
+				return k8sClient.Status().Update(ctx, cc)
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && meta.IsStatusConditionTrue(cc.Status.Conditions, v1alpha1.ConnectionProbeCondition)
+			}, timeout, 100*time.Millisecond).Should(BeTrue())
+
+			// Ensure the condition turns False once the probe times out, without any further probe.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && meta.IsStatusConditionFalse(cc.Status.Conditions, v1alpha1.ConnectionProbeCondition)
+			}, timeout, interval).Should(BeTrue())
+			Expect(cc.Status.Ready).To(BeTrue())
+		})
+	})
+
+	Context("When reconciling a resource with certificate references but without CAPI ClusterRef", func() {
+		var (
+			testName           = "test4"
+			testClusterConnect = types.NamespacedName{Name: testName}
+			testKubeconfig     = types.NamespacedName{Name: testName + "-kubeconfig", Namespace: "default"}
+		)
+
+		BeforeEach(func() {
+			By("creating certificate Secrets before ClusterConnect object")
+			caCert, caKey, err := certutil.GenerateTestCertificate()
+			Expect(err).NotTo(HaveOccurred())
+			ca = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName + "-ca",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"tls.crt": caCert,
+					"tls.key": caKey,
+				},
+			}
+			Expect(k8sClient.Create(ctx, ca)).To(Succeed())
+
+			ccaCert, ccaKey, err := certutil.GenerateTestCertificate()
+			Expect(err).NotTo(HaveOccurred())
+			cca = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName + "-cca",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"tls.crt": ccaCert,
+					"tls.key": ccaKey,
+				},
+			}
+			Expect(k8sClient.Create(ctx, cca)).To(Succeed())
+
+			cc = &v1alpha1.ClusterConnect{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testName,
+				},
+				Spec: v1alpha1.ClusterConnectSpec{
+					ServerCertRef: &corev1.ObjectReference{Name: testName + "-ca", Namespace: "default"},
+					ClientCertRef: &corev1.ObjectReference{Name: testName + "-cca", Namespace: "default"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, cc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.Get(ctx, testClusterConnect, cc)).To(Succeed())
+
+			By("Cleanup the specific resource instance ClusterConnect")
+			Expect(k8sClient.Delete(ctx, cc)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ca)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cca)).To(Succeed())
+		})
+
+		It("should generate the kubeconfig Secret", func() {
+			// Ensure kubeconfig Secret is generated with expected server url.
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, testKubeconfig, kc); err != nil {
+					return false
+				}
+				kubeconfig, err := clientcmd.Load(kc.Data["value"])
+				return err == nil &&
+					kubeconfig.Clusters[testName].Server == "http://connect-gateway.default.svc:8080/kubernetes/test4"
+			}, timeout, interval).Should(BeTrue())
+			// The Secret is labeled with the cluster name, so that the gateway watches it.
+			Expect(kc.Labels).To(HaveKeyWithValue("cluster.x-k8s.io/cluster-name", testName))
+
+			// Ensure kubeconfig labels are set for the gateway and KubeconfigReady condition is true.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil &&
+					cc.Labels["cluster.x-k8s.io/kubeconfig-name"] == testKubeconfig.Name &&
+					cc.Labels["cluster.x-k8s.io/kubeconfig-namespace"] == testKubeconfig.Namespace &&
+					meta.IsStatusConditionTrue(cc.Status.Conditions, v1alpha1.KubeconfigReadyCondition)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When deleting a ClusterConnect resource", func() {
+		var (
+			testName           = "test5"
+			testClusterConnect = types.NamespacedName{Name: testName}
+			testAuthSecret     = types.NamespacedName{Name: testName + "-agent-token", Namespace: "default"}
+			testKubeconfig     = types.NamespacedName{Name: testName + "-kubeconfig", Namespace: "default"}
+		)
+
+		BeforeEach(func() {
+			By("creating certificate Secrets before ClusterConnect object")
+			caCert, caKey, err := certutil.GenerateTestCertificate()
+			Expect(err).NotTo(HaveOccurred())
+			ca = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName + "-ca",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"tls.crt": caCert,
+					"tls.key": caKey,
+				},
+			}
+			Expect(k8sClient.Create(ctx, ca)).To(Succeed())
+
+			ccaCert, ccaKey, err := certutil.GenerateTestCertificate()
+			Expect(err).NotTo(HaveOccurred())
+			cca = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testName + "-cca",
+					Namespace: "default",
+				},
+				Data: map[string][]byte{
+					"tls.crt": ccaCert,
+					"tls.key": ccaKey,
+				},
+			}
+			Expect(k8sClient.Create(ctx, cca)).To(Succeed())
+
+			cc = &v1alpha1.ClusterConnect{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testName,
+				},
+				Spec: v1alpha1.ClusterConnectSpec{
+					ServerCertRef: &corev1.ObjectReference{Name: testName + "-ca", Namespace: "default"},
+					ClientCertRef: &corev1.ObjectReference{Name: testName + "-cca", Namespace: "default"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, cc)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("Cleanup the certificate Secrets")
+			Expect(k8sClient.Delete(ctx, ca)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cca)).To(Succeed())
+		})
+
+		It("should clean up the derived resources before releasing the finalizer", func() {
+			// Ensure the token and kubeconfig Secrets are created.
+			Eventually(func() bool {
+				return k8sClient.Get(ctx, testAuthSecret, &corev1.Secret{}) == nil &&
+					k8sClient.Get(ctx, testKubeconfig, &corev1.Secret{}) == nil
+			}, timeout, interval).Should(BeTrue())
+
+			Expect(k8sClient.Get(ctx, testClusterConnect, cc)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, cc)).To(Succeed())
+
+			// Ensure the ClusterConnect is gone along with the token and kubeconfig Secrets.
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, testClusterConnect, &v1alpha1.ClusterConnect{})) &&
+					apierrors.IsNotFound(k8sClient.Get(ctx, testAuthSecret, &corev1.Secret{})) &&
+					apierrors.IsNotFound(k8sClient.Get(ctx, testKubeconfig, &corev1.Secret{}))
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When forcing the rotation of the agent token", func() {
+		var (
+			testName           = "test6"
+			testClusterConnect = types.NamespacedName{Name: testName}
+			testAuthSecret     = types.NamespacedName{Name: testName + "-agent-token", Namespace: "default"}
+		)
+
+		BeforeEach(func() {
+			By("creating the custom resource for the Kind ClusterConnect")
+			resource := &v1alpha1.ClusterConnect{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: testName,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &v1alpha1.ClusterConnect{}
+			Expect(k8sClient.Get(ctx, testClusterConnect, resource)).To(Succeed())
+
+			By("Cleanup the specific resource instance ClusterConnect")
+			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+		})
+
+		It("should rotate the token and regenerate the agent manifest", func() {
+			// Ensure status.agentManifest is set.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && cc.Status.AgentManifest != ""
+			}, timeout, interval).Should(BeTrue())
+			manifest := cc.Status.AgentManifest
+
+			tokenSecret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, testAuthSecret, tokenSecret)).To(Succeed())
+			token := string(tokenSecret.Data["token"])
+
+			// Request the rotation.
+			cc.Annotations = map[string]string{v1alpha1.RotateAgentTokenAnnotation: "leaked"}
+			Expect(k8sClient.Update(ctx, cc)).To(Succeed())
+
+			// Ensure the token is replaced without keeping the leaked one, and the annotation is removed.
+			Eventually(func() bool {
+				return k8sClient.Get(ctx, testAuthSecret, tokenSecret) == nil &&
+					string(tokenSecret.Data["token"]) != token
+			}, timeout, interval).Should(BeTrue())
+			Expect(tokenSecret.Data).NotTo(HaveKey("previousToken"))
+
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && cc.Status.AgentManifest != manifest &&
+					!hasAnnotation(cc, v1alpha1.RotateAgentTokenAnnotation)
+			}, timeout, interval).Should(BeTrue())
+		})
+	})
+
+	Context("When reconciling a resource with CAPI ClusterRef to a KubeadmControlPlane", func() {
+		const kubeadmManifestPath = "/etc/kubernetes/manifests/connect-agent.yaml"
+
+		var kcpGVK = schema.GroupVersionKind{
+			Group:   "controlplane.cluster.x-k8s.io",
+			Version: "v1beta2",
+			Kind:    "KubeadmControlPlane",
+		}
+
+		newCluster := func(name string, topology clusterv1.Topology) *clusterv1.Cluster {
+			return &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+				},
+				Spec: clusterv1.ClusterSpec{
+					ControlPlaneRef: clusterv1.ContractVersionedObjectReference{
+						APIGroup: kcpGVK.Group,
+						Kind:     kcpGVK.Kind,
+						Name:     name,
+					},
+					Topology: topology,
+				},
+			}
+		}
+
+		newClusterConnect := func(name string) *v1alpha1.ClusterConnect {
+			return &v1alpha1.ClusterConnect{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: name,
+				},
+				Spec: v1alpha1.ClusterConnectSpec{
+					ClusterRef: &corev1.ObjectReference{
+						Name:       name,
+						Namespace:  "default",
+						APIVersion: "cluster.x-k8s.io/v1beta2",
+						Kind:       "Cluster",
+					},
+				},
+			}
+		}
+
+		It("should set the kubeadm static pod manifest path in topology mode", func() {
+			testName := "test7"
+			testCluster := types.NamespacedName{Name: testName, Namespace: "default"}
+
+			cl = newCluster(testName, clusterv1.Topology{
+				ClassRef: clusterv1.ClusterClassRef{Name: "baseline-v0.0.1"},
+				Version:  "v1.30.6",
+			})
+			Expect(k8sClient.Create(ctx, cl)).To(Succeed())
+			cc = newClusterConnect(testName)
+			Expect(k8sClient.Create(ctx, cc)).To(Succeed())
+
+			// Ensure Cluster spec is patched with the agent config at the kubeadm static pod manifest path.
+			Eventually(func() bool {
+				if err := k8sClient.Get(ctx, testCluster, cl); err != nil || len(cl.Spec.Topology.Variables) != 1 {
+					return false
+				}
+				agentConfig := &ConnectAgentConfig{}
+				return cl.Spec.Topology.Variables[0].Name == "connectAgentManifest" &&
+					json.Unmarshal(cl.Spec.Topology.Variables[0].Value.Raw, agentConfig) == nil &&
+					agentConfig.Path == kubeadmManifestPath
+			}, timeout, interval).Should(BeTrue())
+
+			By("Cleanup the specific resource instance ClusterConnect and Cluster")
+			Expect(k8sClient.Delete(ctx, cc)).To(Succeed())
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Name: testName}, &v1alpha1.ClusterConnect{}))
+			}, timeout, interval).Should(BeTrue())
+			Expect(k8sClient.Delete(ctx, cl)).To(Succeed())
+		})
+
+		It("should inject the agent file into spec.kubeadmConfigSpec.files in legacy mode", func() {
+			testName := "test8"
+			testClusterConnect := types.NamespacedName{Name: testName}
+			testControlPlane := types.NamespacedName{Name: testName, Namespace: "default"}
+
+			kcp := &unstructured.Unstructured{}
+			kcp.SetGroupVersionKind(kcpGVK)
+			kcp.SetName(testName)
+			kcp.SetNamespace("default")
+			Expect(unstructured.SetNestedField(kcp.Object, "v1.30.6", "spec", "version")).To(Succeed())
+			Expect(unstructured.SetNestedMap(kcp.Object, map[string]interface{}{
+				"apiGroup": "infrastructure.cluster.x-k8s.io",
+				"kind":     "DockerMachineTemplate",
+				"name":     testName,
+			}, "spec", "machineTemplate", "spec", "infrastructureRef")).To(Succeed())
+			Expect(unstructured.SetNestedSlice(kcp.Object, []interface{}{
+				map[string]interface{}{"path": "/etc/existing.conf", "content": "existing"},
+			}, "spec", "kubeadmConfigSpec", "files")).To(Succeed())
+			Expect(k8sClient.Create(ctx, kcp)).To(Succeed())
+
+			cl = newCluster(testName, clusterv1.Topology{})
+			Expect(k8sClient.Create(ctx, cl)).To(Succeed())
+			cc = newClusterConnect(testName)
+			Expect(k8sClient.Create(ctx, cc)).To(Succeed())
+
+			agentFiles := func() []map[string]interface{} {
+				files, _, err := unstructured.NestedSlice(kcp.Object, "spec", "kubeadmConfigSpec", "files")
+				Expect(err).NotTo(HaveOccurred())
+				agentFiles := []map[string]interface{}{}
+				for _, file := range files {
+					if fileMap := file.(map[string]interface{}); fileMap["path"] != "/etc/existing.conf" {
+						agentFiles = append(agentFiles, fileMap)
+					}
+				}
+				return agentFiles
+			}
+
+			// Ensure the agent file is added next to the existing file at the kubeadm static pod manifest path.
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, testClusterConnect, cc)
+				return err == nil && cc.Status.AgentManifest != "" &&
+					meta.IsStatusConditionTrue(cc.Status.Conditions, v1alpha1.ClusterSpecUpdatedCondition) &&
+					k8sClient.Get(ctx, testControlPlane, kcp) == nil &&
+					len(agentFiles()) == 1 &&
+					agentFiles()[0]["path"] == kubeadmManifestPath &&
+					agentFiles()[0]["content"] == cc.Status.AgentManifest
+			}, timeout, interval).Should(BeTrue())
+			_, found, _ := unstructured.NestedSlice(kcp.Object, "spec", "files")
+			Expect(found).To(BeFalse())
+
+			// Ensure the agent file is removed from the KubeadmControlPlane when the ClusterConnect is deleted.
+			Expect(k8sClient.Delete(ctx, cc)).To(Succeed())
+			Eventually(func() bool {
+				return apierrors.IsNotFound(k8sClient.Get(ctx, testClusterConnect, &v1alpha1.ClusterConnect{})) &&
+					k8sClient.Get(ctx, testControlPlane, kcp) == nil &&
+					len(agentFiles()) == 0
+			}, timeout, interval).Should(BeTrue())
+
+			By("Cleanup the Cluster and KubeadmControlPlane")
+			Expect(k8sClient.Delete(ctx, cl)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, kcp)).To(Succeed())
+		})
+	})
+
+	Context("When a ClusterConnect was ready before the first ready timestamp was recorded", func() {
+		It("should backfill the first ready timestamp without observing the time to ready", func() {
+			created := metav1.NewTime(time.Now().Add(-72 * time.Hour).Truncate(time.Second))
+			readySince := metav1.NewTime(time.Now().Add(-71 * time.Hour).Truncate(time.Second))
+			cc := &v1alpha1.ClusterConnect{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-backfill", CreationTimestamp: created},
+				Status:     v1alpha1.ClusterConnectStatus{Ready: true},
+			}
+			for _, condition := range []string{
+				v1alpha1.AuthTokenReadyCondition,
+				v1alpha1.AgentManifestGeneratedCondition,
+				v1alpha1.ControlPlaneEndpointSetCondition,
+			} {
+				cc.Status.Conditions = append(cc.Status.Conditions, metav1.Condition{
+					Type:               condition,
+					Status:             metav1.ConditionTrue,
+					Reason:             v1alpha1.ReadyReason,
+					LastTransitionTime: created,
+				})
+			}
+			cc.Status.Conditions[1].LastTransitionTime = readySince
+			// The connection probe is not a part of the provisioning.
+			cc.Status.Conditions = append(cc.Status.Conditions, metav1.Condition{
+				Type:               v1alpha1.ConnectionProbeCondition,
+				Status:             metav1.ConditionTrue,
+				Reason:             v1alpha1.ReadyReason,
+				LastTransitionTime: metav1.Now(),
+			})
+
+			r := &ClusterConnectReconciler{}
+			Expect(r.updateStatus(ctx, cc)).To(BeFalse())
+			Expect(cc.Status.Ready).To(BeTrue())
+			Expect(cc.Status.FirstReadyTimestamp).To(Equal(&readySince))
+
+			// The first ready timestamp is kept afterwards.
+			Expect(r.updateStatus(ctx, cc)).To(BeFalse())
+			Expect(cc.Status.FirstReadyTimestamp).To(Equal(&readySince))
 		})
 	})
 })
@@ -112,6 +112,19 @@ func setControlPlaneEndpointSetConditionTrue(cc *v1alpha1.ClusterConnect, messag
 	})
 }
 
+func setControlPlaneEndpointSetConditionFalse(cc *v1alpha1.ClusterConnect, message ...string) {
+	conditionMessage := ""
+	if len(message) > 0 {
+		conditionMessage = message[0]
+	}
+	v1beta2conditions.Set(cc, metav1.Condition{
+		Type:    v1alpha1.ControlPlaneEndpointSetCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.NotReadyReason,
+		Message: conditionMessage,
+	})
+}
+
 func setClusterSpecReadyConditionTrue(cc *v1alpha1.ClusterConnect, message ...string) { //nolint:unparam
 	conditionMessage := ""
 	if len(message) > 0 {
@@ -177,6 +190,39 @@ func setKubeconfigReadyConditionTrue(cc *v1alpha1.ClusterConnect, message ...str
 	})
 }
 
+func setKubeconfigReadyConditionFalse(cc *v1alpha1.ClusterConnect, message ...string) {
+	conditionMessage := ""
+	if len(message) > 0 {
+		conditionMessage = message[0]
+	}
+	v1beta2conditions.Set(cc, metav1.Condition{
+		Type:    v1alpha1.KubeconfigReadyCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.NotReadyReason,
+		Message: conditionMessage,
+	})
+}
+
+// setKubeconfigCorrectedCondition sets KubeconfigReadyCondition to True after the kubeconfig Secret was corrected.
+func setKubeconfigCorrectedCondition(cc *v1alpha1.ClusterConnect, message string) {
+	v1beta2conditions.Set(cc, metav1.Condition{
+		Type:    v1alpha1.KubeconfigReadyCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.KubeconfigCorrectedReason,
+		Message: message,
+	})
+}
+
+// setKubeconfigDriftedCondition sets KubeconfigReadyCondition to False while the correction of the kubeconfig Secret is delayed.
+func setKubeconfigDriftedCondition(cc *v1alpha1.ClusterConnect, message string) {
+	v1beta2conditions.Set(cc, metav1.Condition{
+		Type:    v1alpha1.KubeconfigReadyCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.KubeconfigDriftedReason,
+		Message: message,
+	})
+}
+
 func setConnectionProbeConditionTrue(cc *v1alpha1.ClusterConnect, message ...string) {
 	conditionMessage := ""
 	if len(message) > 0 {
@@ -201,3 +247,39 @@ func setConnectionProbeConditionFalse(cc *v1alpha1.ClusterConnect, message ...st
 		Message: conditionMessage,
 	})
 }
+
+// updateSuspendedCondition sets SuspendedCondition based on spec.suspended.
+// The condition is added only once the ClusterConnect is suspended, and set to False when the access is restored.
+func updateSuspendedCondition(cc *v1alpha1.ClusterConnect) {
+	if cc.Spec.Suspended {
+		setSuspendedConditionTrue(cc, "Access through the connection gateway is suspended")
+	} else if v1beta2conditions.Has(cc, v1alpha1.SuspendedCondition) {
+		setSuspendedConditionFalse(cc, "Access through the connection gateway is restored")
+	}
+}
+
+func setSuspendedConditionTrue(cc *v1alpha1.ClusterConnect, message ...string) {
+	conditionMessage := ""
+	if len(message) > 0 {
+		conditionMessage = message[0]
+	}
+	v1beta2conditions.Set(cc, metav1.Condition{
+		Type:    v1alpha1.SuspendedCondition,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.SuspendedReason,
+		Message: conditionMessage,
+	})
+}
+
+func setSuspendedConditionFalse(cc *v1alpha1.ClusterConnect, message ...string) {
+	conditionMessage := ""
+	if len(message) > 0 {
+		conditionMessage = message[0]
+	}
+	v1beta2conditions.Set(cc, metav1.Condition{
+		Type:    v1alpha1.SuspendedCondition,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.ResumedReason,
+		Message: conditionMessage,
+	})
+}
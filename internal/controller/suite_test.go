@@ -108,6 +108,12 @@ var _ = BeforeSuite(func() {
 	}).SetupWithManager(ctx, k8sManager, 1*time.Second, 1)
 	Expect(err).ToNot(HaveOccurred())
 
+	err = (&ClusterReconciler{
+		Client:     k8sManager.GetClient(),
+		Annotation: testAutoConnectAnnotation,
+	}).SetupWithManager(k8sManager)
+	Expect(err).ToNot(HaveOccurred())
+
 	go func() {
 		defer GinkgoRecover()
 		err = k8sManager.Start(ctx)
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+// Package controller defines the metrics of the connect-controller. They are registered in the controller-runtime
+// registry, so that only the connect-controller, which imports this package, serves them.
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Metrics of the connect-controller, served by the controller-runtime metrics server.
+var (
+	ReconcilePhaseDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "connect_controller",
+			Name:      "reconcile_phase_duration_seconds",
+			Help:      "Duration in seconds of the ClusterConnect reconcile phases, partitioned by phase.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"phase"},
+	)
+	ReconcilePhaseErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "connect_controller",
+			Name:      "reconcile_phase_errors_total",
+			Help:      "Total number of failed ClusterConnect reconcile phases, partitioned by phase.",
+		},
+		[]string{"phase"},
+	)
+	ClusterConnectsReady = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "connect_controller",
+		Name:      "clusterconnects_ready",
+		Help:      "Number of ClusterConnects that are ready.",
+	})
+	ClusterConnectsProbeHealthy = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "connect_controller",
+		Name:      "clusterconnects_probe_healthy",
+		Help:      "Number of ClusterConnects whose connection probe succeeds.",
+	})
+	ClusterConnectTimeToReady = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "connect_controller",
+		Name:      "clusterconnect_time_to_ready_seconds",
+		Help:      "Duration in seconds from the creation of a ClusterConnect until it became ready for the first time.",
+		// From 10 seconds to about 11 hours, as the onboarding includes the provisioning of the cluster.
+		Buckets: prometheus.ExponentialBuckets(10, 2, 13),
+	})
+)
+
+// clusterConnectStates tracks the state of the ClusterConnects for the ClusterConnectsReady and
+// ClusterConnectsProbeHealthy gauges.
+var clusterConnectStates = struct {
+	sync.Mutex
+	states map[string]clusterConnectState
+}{states: map[string]clusterConnectState{}}
+
+type clusterConnectState struct {
+	ready        bool
+	probeHealthy bool
+}
+
+// SetClusterConnectState records the state of a given ClusterConnect and updates the gauges.
+func SetClusterConnectState(name string, ready, probeHealthy bool) {
+	clusterConnectStates.Lock()
+	defer clusterConnectStates.Unlock()
+	clusterConnectStates.states[name] = clusterConnectState{ready: ready, probeHealthy: probeHealthy}
+	updateClusterConnectGauges()
+}
+
+// DeleteClusterConnectState forgets the state of a given deleted ClusterConnect and updates the gauges.
+func DeleteClusterConnectState(name string) {
+	clusterConnectStates.Lock()
+	defer clusterConnectStates.Unlock()
+	delete(clusterConnectStates.states, name)
+	updateClusterConnectGauges()
+}
+
+func updateClusterConnectGauges() {
+	ready, probeHealthy := 0, 0
+	for _, state := range clusterConnectStates.states {
+		if state.ready {
+			ready++
+		}
+		if state.probeHealthy {
+			probeHealthy++
+		}
+	}
+	ClusterConnectsReady.Set(float64(ready))
+	ClusterConnectsProbeHealthy.Set(float64(probeHealthy))
+}
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(ReconcilePhaseDuration)
+	ctrlmetrics.Registry.MustRegister(ReconcilePhaseErrors)
+	ctrlmetrics.Registry.MustRegister(ClusterConnectsReady)
+	ctrlmetrics.Registry.MustRegister(ClusterConnectsProbeHealthy)
+	ctrlmetrics.Registry.MustRegister(ClusterConnectTimeToReady)
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterConnectStateGauges(t *testing.T) {
+	SetClusterConnectState("cc-1", true, true)
+	SetClusterConnectState("cc-2", true, false)
+	SetClusterConnectState("cc-3", false, false)
+	assert.Equal(t, 2.0, testutil.ToFloat64(ClusterConnectsReady))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ClusterConnectsProbeHealthy))
+
+	// The state of a ClusterConnect is replaced, not added.
+	SetClusterConnectState("cc-2", true, true)
+	assert.Equal(t, 2.0, testutil.ToFloat64(ClusterConnectsReady))
+	assert.Equal(t, 2.0, testutil.ToFloat64(ClusterConnectsProbeHealthy))
+
+	DeleteClusterConnectState("cc-1")
+	DeleteClusterConnectState("cc-unknown")
+	assert.Equal(t, 1.0, testutil.ToFloat64(ClusterConnectsReady))
+	assert.Equal(t, 1.0, testutil.ToFloat64(ClusterConnectsProbeHealthy))
+}
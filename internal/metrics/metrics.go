@@ -4,9 +4,14 @@
 package metrics
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// OtherTunnels is the tunnel label of the tunnels over the MaxTunnelLabels cap.
+const OtherTunnels = "other"
+
 var (
 	ConnectionCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -15,11 +20,23 @@ var (
 		},
 		[]string{"status"},
 	)
-	RequestLatency = prometheus.NewHistogram(prometheus.HistogramOpts{
-		Name:    "request_latency_seconds",
-		Help:    "Request latency of /kubernetes endpoint in seconds",
-		Buckets: prometheus.DefBuckets,
-	})
+	RequestLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "request_latency_seconds",
+			Help:    "Request latency of /kubernetes endpoint in seconds, excluding the watches and streams, partitioned by tunnel, verb and resource group.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"tunnel", "verb", "group"},
+	)
+	StreamDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "proxied_stream_duration_seconds",
+			Help: "Duration in seconds of the watches and streams (exec, attach, port-forward and followed logs) of /kubernetes endpoint, partitioned by tunnel, verb, resource group and upgrade type.",
+			// From 1 second to about 3 days.
+			Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+		},
+		[]string{"tunnel", "verb", "group", "upgrade"},
+	)
 	KubeconfigRetrievalDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
 		Namespace: "kubernetes",
 		Subsystem: "secret",
@@ -30,15 +47,105 @@ var (
 	ProxiedHttpResponseCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "proxied_http_response_codes",
-			Help: "Count of HTTP response codes for proxied requests",
+			Help: "Count of HTTP response codes for proxied requests, partitioned by code, tunnel, verb, resource group and upgrade type.",
+		},
+		[]string{"code", "tunnel", "verb", "group", "upgrade"},
+	)
+	AuditEventsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "audit_events_total",
+			Help: "Total number of audit events, partitioned by sink (log, file or webhook) and result (written, failed or dropped).",
+		},
+		[]string{"sink", "result"},
+	)
+	RateLimitedRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limited_requests_total",
+			Help: "Total number of /kubernetes requests rejected by a rate limit, partitioned by limit (tunnel or user) and reason (rate or concurrency).",
+		},
+		[]string{"limit", "reason"},
+	)
+	ClientCacheLookupsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_client_cache_lookups_total",
+			Help: "Total number of tunnel HTTP client lookups in the client cache, partitioned by result (hit or miss).",
 		},
-		[]string{"code"},
+		[]string{"result"},
+	)
+	ClientCacheEvictionsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_client_cache_evictions_total",
+			Help: "Total number of tunnel HTTP clients evicted from the client cache, partitioned by reason (size, idle, invalidated or disconnected).",
+		},
+		[]string{"reason"},
+	)
+	ClientCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_client_cache_size",
+		Help: "Number of tunnel HTTP clients in the client cache.",
+	})
+	TokenCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "kubernetes",
+			Subsystem: "secret",
+			Name:      "token_cache_lookups_total",
+			Help:      "Total number of agent token lookups in the Secret cache, partitioned by result (hit or miss).",
+		},
+		[]string{"result"},
 	)
 )
 
 func init() {
 	prometheus.MustRegister(ConnectionCounter)
-	prometheus.MustRegister(RequestLatency) //TODO: refactor
+	prometheus.MustRegister(RequestLatency)
+	prometheus.MustRegister(StreamDuration)
 	prometheus.MustRegister(KubeconfigRetrievalDuration)
 	prometheus.MustRegister(ProxiedHttpResponseCounter)
+	prometheus.MustRegister(TokenCacheCounter)
+	prometheus.MustRegister(RateLimitedRequestsCounter)
+	prometheus.MustRegister(AuditEventsCounter)
+	prometheus.MustRegister(ClientCacheLookupsCounter)
+	prometheus.MustRegister(ClientCacheEvictionsCounter)
+	prometheus.MustRegister(ClientCacheSize)
+}
+
+// tunnelLabels tracks the tunnels with their own label, up to a maximum number of tunnels.
+var tunnelLabels = struct {
+	sync.Mutex
+	max     int
+	tunnels map[string]bool
+}{tunnels: map[string]bool{}}
+
+// SetMaxTunnelLabels caps the number of tunnels labeled with their tunnel ID, to bound the cardinality of the
+// metrics. The requests of the other tunnels are labeled OtherTunnels. 0 disables the cap.
+func SetMaxTunnelLabels(maxTunnels int) {
+	tunnelLabels.Lock()
+	defer tunnelLabels.Unlock()
+	tunnelLabels.max = maxTunnels
+}
+
+// TunnelLabel returns the tunnel label of a given tunnel ID. The first tunnels up to the cap keep their own label.
+func TunnelLabel(tunnelID string) string {
+	tunnelLabels.Lock()
+	defer tunnelLabels.Unlock()
+	if tunnelLabels.max <= 0 || tunnelLabels.tunnels[tunnelID] {
+		return tunnelID
+	}
+	if len(tunnelLabels.tunnels) >= tunnelLabels.max {
+		return OtherTunnels
+	}
+	tunnelLabels.tunnels[tunnelID] = true
+	return tunnelID
+}
+
+// ReleaseTunnelLabel releases the label of a deleted tunnel, for another tunnel to take it, and deletes the metrics
+// labeled with it.
+func ReleaseTunnelLabel(tunnelID string) {
+	tunnelLabels.Lock()
+	delete(tunnelLabels.tunnels, tunnelID)
+	tunnelLabels.Unlock()
+
+	labels := prometheus.Labels{"tunnel": tunnelID}
+	RequestLatency.DeletePartialMatch(labels)
+	StreamDuration.DeletePartialMatch(labels)
+	ProxiedHttpResponseCounter.DeletePartialMatch(labels)
 }
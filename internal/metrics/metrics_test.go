@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTunnelLabel(t *testing.T) {
+	assert.Equal(t, "tunnel-a", TunnelLabel("tunnel-a"))
+
+	SetMaxTunnelLabels(2)
+	defer SetMaxTunnelLabels(0)
+	defer ReleaseTunnelLabel("tunnel-b")
+	defer ReleaseTunnelLabel("tunnel-c")
+	assert.Equal(t, "tunnel-b", TunnelLabel("tunnel-b"))
+	assert.Equal(t, "tunnel-c", TunnelLabel("tunnel-c"))
+	assert.Equal(t, OtherTunnels, TunnelLabel("tunnel-d"))
+	// The tunnels under the cap keep their label.
+	assert.Equal(t, "tunnel-b", TunnelLabel("tunnel-b"))
+
+	SetMaxTunnelLabels(0)
+	assert.Equal(t, "tunnel-d", TunnelLabel("tunnel-d"))
+}
+
+func TestReleaseTunnelLabel(t *testing.T) {
+	SetMaxTunnelLabels(1)
+	defer SetMaxTunnelLabels(0)
+	defer ReleaseTunnelLabel("tunnel-f")
+	assert.Equal(t, "tunnel-e", TunnelLabel("tunnel-e"))
+	assert.Equal(t, OtherTunnels, TunnelLabel("tunnel-f"))
+	ProxiedHttpResponseCounter.WithLabelValues("200", "tunnel-e", "get", "", "").Inc()
+
+	// The label of a deleted tunnel is released, with its metrics.
+	ReleaseTunnelLabel("tunnel-e")
+	assert.Equal(t, "tunnel-f", TunnelLabel("tunnel-f"))
+	assert.Equal(t, 0, testutil.CollectAndCount(ProxiedHttpResponseCounter, "proxied_http_response_codes"))
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	authnv1 "k8s.io/api/authentication/v1"
+)
+
+// Impersonation maps the JWT claims of the authenticated users to a Kubernetes user and groups, which the gateway
+// impersonates on the edge clusters so that their RBAC applies per user instead of the admin kubeconfig identity.
+type Impersonation struct {
+	// UsernameClaim is the claim of the username. The subject is used if the claim is missing.
+	UsernameClaim string
+	// GroupsClaims are the claims of the groups, either a string or a list of strings. Nested claims are
+	// separated by dots, e.g. realm_access.roles.
+	GroupsClaims []string
+	// UsernamePrefix and GroupsPrefix are prepended to the username and groups, to keep them apart from the
+	// users and groups of the edge clusters.
+	UsernamePrefix string
+	GroupsPrefix   string
+}
+
+// Middleware strips the impersonation headers set by the clients, and sets those of the user authenticated by the
+// AuthMiddleware, so it must come after it. The requests without an authenticated user are rejected, as they would
+// be proxied with the admin kubeconfig identity.
+func (im Impersonation) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		stripImpersonationHeaders(req.Header)
+
+		claims, ok := ClaimsFromContext(req.Context())
+		if !ok {
+			log.Warnf("Rejecting request to %s without an authenticated user to impersonate", req.URL.Path)
+			http.Error(rw, "no authenticated user to impersonate", http.StatusForbidden)
+			return
+		}
+		user, err := im.userInfo(claims)
+		if err != nil {
+			log.Infof("Rejecting request to %s: %v", req.URL.Path, err)
+			http.Error(rw, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		req.Header.Set(authnv1.ImpersonateUserHeader, user.Username)
+		if user.UID != "" {
+			req.Header.Set(authnv1.ImpersonateUIDHeader, user.UID)
+		}
+		for _, group := range user.Groups {
+			req.Header.Add(authnv1.ImpersonateGroupHeader, group)
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// userInfo maps given JWT claims to the Kubernetes user to impersonate.
+func (im Impersonation) userInfo(claims jwt.Claims) (authnv1.UserInfo, error) {
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return authnv1.UserInfo{}, fmt.Errorf("unsupported JWT claims %T", claims)
+	}
+
+	subject, _ := mapClaims.GetSubject()
+	username := subject
+	if im.UsernameClaim != "" {
+		if value, ok := lookupClaim(mapClaims, im.UsernameClaim).(string); ok && value != "" {
+			username = value
+		}
+	}
+	if username == "" {
+		return authnv1.UserInfo{}, fmt.Errorf("JWT has neither %s nor sub claim", im.UsernameClaim)
+	}
+
+	user := authnv1.UserInfo{Username: im.UsernamePrefix + username, UID: subject}
+	seen := map[string]bool{}
+	for _, claim := range im.GroupsClaims {
+		for _, group := range stringsClaim(lookupClaim(mapClaims, claim)) {
+			if group != "" && !seen[group] {
+				seen[group] = true
+				user.Groups = append(user.Groups, im.GroupsPrefix+group)
+			}
+		}
+	}
+	return user, nil
+}
+
+// lookupClaim returns the value of a claim, following the dots of the nested claims.
+func lookupClaim(claims map[string]interface{}, name string) interface{} {
+	var value interface{} = claims
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func stringsClaim(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// StripImpersonationMiddleware strips the impersonation headers set by the clients, so that the requests proxied with
+// the admin kubeconfig identity can't impersonate another user when the impersonation is disabled.
+func StripImpersonationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		stripImpersonationHeaders(req.Header)
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// stripImpersonationHeaders removes the Impersonate-User, Impersonate-Group, Impersonate-Uid and Impersonate-Extra-*
+// headers, so that the clients can't choose their identity on the edge clusters.
+func stripImpersonationHeaders(header http.Header) {
+	prefix := textproto.CanonicalMIMEHeaderKey("Impersonate-")
+	for key := range header {
+		if strings.HasPrefix(textproto.CanonicalMIMEHeaderKey(key), prefix) {
+			delete(header, key)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Impersonation", func() {
+	var received http.Header
+	record := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(im Impersonation, claims jwt.Claims) *httptest.ResponseRecorder {
+		received = nil
+		req := httptest.NewRequest(http.MethodGet, "/kubernetes/tunnel-a/api/v1/pods", nil)
+		req.Header.Set("Impersonate-User", "admin")
+		req.Header.Add("Impersonate-Group", "system:masters")
+		req.Header.Set("Impersonate-Extra-Scopes", "all")
+		req.Header["impersonate-uid"] = []string{"0"}
+		if claims != nil {
+			req = req.WithContext(ContextWithClaims(req.Context(), claims))
+		}
+		rr := httptest.NewRecorder()
+		im.Middleware(record).ServeHTTP(rr, req)
+		return rr
+	}
+
+	It("should impersonate the user and groups of the JWT claims", func() {
+		im := Impersonation{
+			UsernameClaim:  "preferred_username",
+			GroupsClaims:   []string{"groups", "realm_access.roles"},
+			UsernamePrefix: "oidc:",
+			GroupsPrefix:   "oidc:",
+		}
+		claims := jwt.MapClaims{
+			"sub":                "0a2b",
+			"preferred_username": "alice",
+			"groups":             "edge-operators",
+			"realm_access":       map[string]interface{}{"roles": []interface{}{"cl-r", "edge-operators", 1}},
+		}
+
+		Expect(serve(im, claims).Code).To(Equal(http.StatusOK))
+		Expect(received.Values("Impersonate-User")).To(Equal([]string{"oidc:alice"}))
+		Expect(received.Values("Impersonate-Uid")).To(Equal([]string{"0a2b"}))
+		Expect(received.Values("Impersonate-Group")).To(Equal([]string{"oidc:edge-operators", "oidc:cl-r"}))
+		Expect(received).NotTo(HaveKey("Impersonate-Extra-Scopes"))
+		Expect(received).NotTo(HaveKey("impersonate-uid"))
+	})
+
+	It("should fall back to the subject without a username claim", func() {
+		Expect(serve(Impersonation{UsernameClaim: "email"}, jwt.MapClaims{"sub": "0a2b"}).Code).To(Equal(http.StatusOK))
+		Expect(received.Values("Impersonate-User")).To(Equal([]string{"0a2b"}))
+		Expect(received).NotTo(HaveKey("Impersonate-Group"))
+	})
+
+	It("should reject the requests without an authenticated user", func() {
+		Expect(serve(Impersonation{}, nil).Code).To(Equal(http.StatusForbidden))
+		Expect(serve(Impersonation{UsernameClaim: "email"}, jwt.MapClaims{}).Code).To(Equal(http.StatusForbidden))
+		Expect(received).To(BeNil())
+	})
+
+	It("should strip the impersonation headers when the impersonation is disabled", func() {
+		req := httptest.NewRequest(http.MethodGet, "/kubernetes/tunnel-a/api/v1/pods", nil)
+		req.Header.Set("Impersonate-User", "admin")
+		req.Header["impersonate-uid"] = []string{"0"}
+		req.Header.Set("Accept", "application/json")
+		rr := httptest.NewRecorder()
+		StripImpersonationMiddleware(record).ServeHTTP(rr, req)
+
+		Expect(rr.Code).To(Equal(http.StatusOK))
+		Expect(received).To(HaveLen(1))
+		Expect(received.Get("Accept")).To(Equal("application/json"))
+	})
+})
@@ -5,6 +5,7 @@ package middleware
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,6 +22,42 @@ import (
 
 var log = dazl.GetPackageLogger()
 
+type claimsKey struct{}
+
+type claimsRecorderKey struct{}
+
+// ClaimsFromContext returns the JWT claims of a request authenticated by the AuthMiddleware.
+func ClaimsFromContext(ctx context.Context) (jwt.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(jwt.Claims)
+	return claims, ok
+}
+
+// ContextWithClaims returns a copy of a given context with the JWT claims of an authenticated request. The claims
+// are also recorded by the ClaimsRecorder of the context, if any.
+func ContextWithClaims(ctx context.Context, claims jwt.Claims) context.Context {
+	if recorder, ok := ctx.Value(claimsRecorderKey{}).(*ClaimsRecorder); ok {
+		recorder.claims = claims
+	}
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsRecorder records the JWT claims of a request once they are validated by the AuthMiddleware, for the
+// middlewares that come before it, e.g. to audit the requests that it rejects.
+type ClaimsRecorder struct {
+	claims jwt.Claims
+}
+
+// ContextWithClaimsRecorder returns a copy of a given context with a new ClaimsRecorder.
+func ContextWithClaimsRecorder(ctx context.Context) (context.Context, *ClaimsRecorder) {
+	recorder := &ClaimsRecorder{}
+	return context.WithValue(ctx, claimsRecorderKey{}, recorder), recorder
+}
+
+// Claims returns the recorded JWT claims, if any.
+func (r *ClaimsRecorder) Claims() (jwt.Claims, bool) {
+	return r.claims, r.claims != nil
+}
+
 type JwtAuthenticator interface {
 	ParseAndValidate(string) (jwt.Claims, error)
 }
@@ -31,9 +68,10 @@ type JwtAuthorization struct {
 	RbacEnabled      bool
 }
 
+// extractTunnelId returns the tunnel ID of the requests to /kubernetes/{tunnel_id}/ and /services/{tunnel_id}/.
 func extractTunnelId(req *http.Request) (string, error) {
 	segments := strings.Split(req.URL.Path, "/")
-	if len(segments) >= 3 && segments[1] == "kubernetes" && segments[2] != "" {
+	if len(segments) >= 3 && (segments[1] == "kubernetes" || segments[1] == "services") && segments[2] != "" {
 		return segments[2], nil
 	}
 	return "", errors.New("invalid path format")
@@ -50,6 +88,11 @@ func extractProjectIdFromTunnel(tunnelId string) (string, error) {
 	return projectId, nil
 }
 
+// ProjectIDFromTunnel returns the project UUID prefix of a given tunnel ID.
+func ProjectIDFromTunnel(tunnelID string) (string, error) {
+	return extractProjectIdFromTunnel(tunnelID)
+}
+
 func (ja *JwtAuthorization) checkOpaPolicies(req *http.Request, claims jwt.Claims) error {
 	tunnelId, err := extractTunnelId(req)
 	if err != nil {
@@ -109,6 +152,8 @@ func (ja *JwtAuthorization) AuthMiddleware(next http.Handler) http.Handler {
 			log.Infow("Unauthorized", dazl.Error(err))
 			return
 		}
+		// The claims are set before the authorization, so that the rejected requests are audited with their user.
+		req = req.WithContext(ContextWithClaims(req.Context(), claims))
 		if ja.RbacEnabled {
 			err = ja.checkOpaPolicies(req, claims)
 			if err != nil {
@@ -4,6 +4,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -38,6 +39,13 @@ var _ = Describe("JwtAuthorization", func() {
 			Expect(id).To(Equal(tunnelId))
 		})
 
+		It("should extract tunnel ID from a service path", func() {
+			req := httptest.NewRequest(http.MethodGet, "/services/"+tunnelId+"/monitoring/grafana:80/", nil)
+			id, err := extractTunnelId(req)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal(tunnelId))
+		})
+
 		It("should return an error for an invalid path", func() {
 			req := httptest.NewRequest(http.MethodGet, "/invalid/path", nil)
 			_, err := extractTunnelId(req)
@@ -72,6 +80,25 @@ var _ = Describe("JwtAuthorization", func() {
 
 			Expect(rr.Code).To(Equal(http.StatusUnauthorized))
 		})
+
+		It("should record the claims of a valid token for the rejected requests", func() {
+			jwtAuth.RbacEnabled = true
+			ctx, recorder := ContextWithClaimsRecorder(context.Background())
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/invalid/path", nil)
+			req.Header.Set("Authorization", "Bearer valid-token")
+			rr := httptest.NewRecorder()
+
+			handler := jwtAuth.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			handler.ServeHTTP(rr, req)
+
+			Expect(rr.Code).To(Equal(http.StatusUnauthorized))
+			claims, ok := recorder.Claims()
+			Expect(ok).To(BeTrue())
+			Expect(claims.GetSubject()).To(Equal("1234567890"))
+		})
 	})
 })
 
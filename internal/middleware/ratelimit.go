@@ -0,0 +1,229 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+)
+
+const (
+	// limiterIdleTimeout is the time after which the limiter of an idle tunnel or user is forgotten.
+	limiterIdleTimeout = 10 * time.Minute
+	// concurrencyRetryAfter is the Retry-After of the requests rejected by a concurrent requests cap.
+	concurrencyRetryAfter = time.Second
+)
+
+// RateLimit configures a token bucket of QPS requests per second with a given burst, and a cap of MaxInflight
+// concurrent requests. A zero QPS or MaxInflight disables the respective limit.
+type RateLimit struct {
+	QPS         float64
+	Burst       int
+	MaxInflight int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.QPS > 0 || l.MaxInflight > 0
+}
+
+// RateLimiter limits the requests to the /kubernetes and /services endpoints per tunnel ID and per JWT subject, so
+// that a single client can't saturate the tunnel of an edge cluster.
+type RateLimiter struct {
+	tunnel RateLimit
+	user   RateLimit
+
+	mu        sync.Mutex
+	limiters  map[string]*keyLimiter
+	lastPrune time.Time
+}
+
+type keyLimiter struct {
+	bucket   *rate.Limiter
+	inflight int
+	lastUsed time.Time
+}
+
+// NewRateLimiter creates a RateLimiter with given per-tunnel and per-user limits.
+func NewRateLimiter(tunnel, user RateLimit) *RateLimiter {
+	return &RateLimiter{
+		tunnel:   tunnel,
+		user:     user,
+		limiters: map[string]*keyLimiter{},
+	}
+}
+
+// Enabled reports whether any limit is configured.
+func (rl *RateLimiter) Enabled() bool {
+	return rl.tunnel.enabled() || rl.user.enabled()
+}
+
+// Middleware rejects the requests over the limits with a Kubernetes 429 Too Many Requests status.
+// The user limits apply only to the requests authenticated by the AuthMiddleware.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var keys []limiterKey
+		if tunnelID, err := extractTunnelId(req); err == nil && rl.tunnel.enabled() {
+			keys = append(keys, limiterKey{kind: "tunnel", id: tunnelID, limit: rl.tunnel})
+		}
+		if claims, ok := ClaimsFromContext(req.Context()); ok && rl.user.enabled() {
+			if subject, err := claims.GetSubject(); err == nil && subject != "" {
+				keys = append(keys, limiterKey{kind: "user", id: subject, limit: rl.user})
+			}
+		}
+
+		release, rejected := rl.acquire(keys, !isLongRunning(req))
+		if rejected != nil {
+			metrics.RateLimitedRequestsCounter.WithLabelValues(rejected.kind, rejected.reason).Inc()
+			log.Debugf("Rejecting request to %s: %s", req.URL.Path, rejected.message)
+			writeTooManyRequests(rw, rejected.message, rejected.retryAfter)
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+type limiterKey struct {
+	kind  string
+	id    string
+	limit RateLimit
+}
+
+type rejection struct {
+	kind       string
+	reason     string
+	message    string
+	retryAfter time.Duration
+}
+
+// acquire takes a token and, if counted, a concurrent request slot from the limiters of given keys. Nothing is
+// taken if any limiter rejects the request.
+func (rl *RateLimiter) acquire(keys []limiterKey, counted bool) (release func(), rejected *rejection) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	rl.prune(now)
+
+	limiters := make([]*keyLimiter, len(keys))
+	reservations := make([]*rate.Reservation, 0, len(keys))
+	cancel := func() {
+		for _, r := range reservations {
+			r.CancelAt(now)
+		}
+	}
+
+	for i, key := range keys {
+		l := rl.limiter(key, now)
+		limiters[i] = l
+
+		if counted && key.limit.MaxInflight > 0 && l.inflight >= key.limit.MaxInflight {
+			cancel()
+			return nil, &rejection{
+				kind:       key.kind,
+				reason:     "concurrency",
+				message:    fmt.Sprintf("too many concurrent requests for %s %s", key.kind, key.id),
+				retryAfter: concurrencyRetryAfter,
+			}
+		}
+
+		if l.bucket != nil {
+			r := l.bucket.ReserveN(now, 1)
+			if delay := r.DelayFrom(now); !r.OK() || delay > 0 {
+				r.CancelAt(now)
+				cancel()
+				return nil, &rejection{
+					kind:       key.kind,
+					reason:     "rate",
+					message:    fmt.Sprintf("too many requests for %s %s", key.kind, key.id),
+					retryAfter: delay,
+				}
+			}
+			reservations = append(reservations, r)
+		}
+	}
+
+	if counted {
+		for _, l := range limiters {
+			l.inflight++
+		}
+	}
+	return func() {
+		if !counted {
+			return
+		}
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		for _, l := range limiters {
+			l.inflight--
+			l.lastUsed = time.Now()
+		}
+	}, nil
+}
+
+// limiter returns the limiter of a given key, creating it on first use.
+func (rl *RateLimiter) limiter(key limiterKey, now time.Time) *keyLimiter {
+	id := key.kind + "/" + key.id
+	l, ok := rl.limiters[id]
+	if !ok {
+		l = &keyLimiter{}
+		if key.limit.QPS > 0 {
+			l.bucket = rate.NewLimiter(rate.Limit(key.limit.QPS), max(key.limit.Burst, 1))
+		}
+		rl.limiters[id] = l
+	}
+	l.lastUsed = now
+	return l
+}
+
+// prune forgets the limiters of the tunnels and users that have been idle for a while.
+// An idle token bucket is full, so forgetting it doesn't change the limits.
+func (rl *RateLimiter) prune(now time.Time) {
+	if now.Sub(rl.lastPrune) < limiterIdleTimeout {
+		return
+	}
+	rl.lastPrune = now
+	for id, l := range rl.limiters {
+		if l.inflight == 0 && now.Sub(l.lastUsed) > limiterIdleTimeout {
+			delete(rl.limiters, id)
+		}
+	}
+}
+
+// isLongRunning reports whether a request is a watch, a log stream or an upgraded stream, which are not
+// counted against the concurrent requests caps, as the Kubernetes API server does.
+func isLongRunning(req *http.Request) bool {
+	query := req.URL.Query()
+	return req.Header.Get("Upgrade") != "" ||
+		query.Get("watch") == "true" || query.Get("watch") == "1" ||
+		query.Get("follow") == "true" ||
+		strings.Contains(req.URL.Path, "/watch/")
+}
+
+// writeTooManyRequests writes a Kubernetes Status with reason TooManyRequests, which the Kubernetes clients
+// retry after the Retry-After delay.
+func writeTooManyRequests(rw http.ResponseWriter, message string, retryAfter time.Duration) {
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+	status := apierrors.NewTooManyRequests(message, seconds).ErrStatus
+	status.APIVersion = "v1"
+	status.Kind = "Status"
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Retry-After", fmt.Sprintf("%d", seconds))
+	rw.WriteHeader(http.StatusTooManyRequests)
+	if err := json.NewEncoder(rw).Encode(status); err != nil {
+		log.Warnf("Failed to write response: %v", err)
+	}
+}
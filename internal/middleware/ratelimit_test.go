@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+)
+
+var _ = Describe("RateLimiter", func() {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(handler http.Handler, path, subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if subject != "" {
+			req = req.WithContext(context.WithValue(req.Context(), claimsKey{}, jwt.MapClaims{"sub": subject}))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	It("should reject the requests over the tunnel rate with a Kubernetes status", func() {
+		handler := NewRateLimiter(RateLimit{QPS: 0.1, Burst: 2}, RateLimit{}).Middleware(ok)
+		rejected := testutil.ToFloat64(metrics.RateLimitedRequestsCounter.WithLabelValues("tunnel", "rate"))
+
+		Expect(serve(handler, "/kubernetes/tunnel-a/api", "").Code).To(Equal(http.StatusOK))
+		Expect(serve(handler, "/kubernetes/tunnel-a/api", "").Code).To(Equal(http.StatusOK))
+
+		rr := serve(handler, "/kubernetes/tunnel-a/api", "")
+		Expect(rr.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(rr.Header().Get("Retry-After")).To(Equal("10"))
+		status := &metav1.Status{}
+		Expect(json.Unmarshal(rr.Body.Bytes(), status)).To(Succeed())
+		Expect(status.Kind).To(Equal("Status"))
+		Expect(status.Reason).To(Equal(metav1.StatusReasonTooManyRequests))
+		Expect(status.Details.RetryAfterSeconds).To(BeEquivalentTo(10))
+		Expect(testutil.ToFloat64(metrics.RateLimitedRequestsCounter.WithLabelValues("tunnel", "rate"))).To(Equal(rejected + 1))
+
+		// The tunnels are limited independently.
+		Expect(serve(handler, "/kubernetes/tunnel-b/api", "").Code).To(Equal(http.StatusOK))
+	})
+
+	It("should not take a tunnel token for a request rejected by the user rate", func() {
+		handler := NewRateLimiter(RateLimit{QPS: 0.1, Burst: 2}, RateLimit{QPS: 0.1, Burst: 1}).Middleware(ok)
+
+		Expect(serve(handler, "/kubernetes/tunnel-a/api", "alice").Code).To(Equal(http.StatusOK))
+		Expect(serve(handler, "/kubernetes/tunnel-a/api", "alice").Code).To(Equal(http.StatusTooManyRequests))
+		Expect(serve(handler, "/kubernetes/tunnel-a/api", "bob").Code).To(Equal(http.StatusOK))
+		Expect(serve(handler, "/kubernetes/tunnel-a/api", "carol").Code).To(Equal(http.StatusTooManyRequests))
+	})
+
+	It("should cap the concurrent requests except for the long-running ones", func() {
+		release := make(chan struct{})
+		started := make(chan struct{})
+		blocking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("block") == "true" {
+				started <- struct{}{}
+				<-release
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+		handler := NewRateLimiter(RateLimit{MaxInflight: 1}, RateLimit{}).Middleware(blocking)
+
+		done := make(chan int)
+		go func() {
+			done <- serve(handler, "/kubernetes/tunnel-a/api?block=true", "").Code
+		}()
+		<-started
+
+		rr := serve(handler, "/kubernetes/tunnel-a/api", "")
+		Expect(rr.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(rr.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(serve(handler, "/kubernetes/tunnel-a/api/v1/pods?watch=true", "").Code).To(Equal(http.StatusOK))
+
+		close(release)
+		Eventually(done).Should(Receive(Equal(http.StatusOK)))
+		Expect(serve(handler, "/kubernetes/tunnel-a/api", "").Code).To(Equal(http.StatusOK))
+	})
+})
@@ -3,53 +3,278 @@
 
 package provider
 
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ProvidersKey is the key of the ConfigMap data holding the provider definitions.
+	ProvidersKey = "providers.yaml"
+
+	defaultFilesPath = "spec.files"
+	defaultFileOwner = "root:root"
+)
+
+// Provider defines how the connect-agent is injected into the ControlPlane objects of a CAPI control plane provider.
+type Provider struct {
+	// Kind is the kind of the ControlPlane object, e.g. KubeadmControlPlane.
+	Kind string `json:"kind"`
+	// APIGroup is the API group of the ControlPlane object. The provider matches any group if empty.
+	APIGroup string `json:"apiGroup,omitempty"`
+	// StaticPodManifestPath is the path of the connect-agent static pod manifest on the control plane nodes.
+	StaticPodManifestPath string `json:"staticPodManifestPath"`
+	// FilesPath is the dot-separated path of the files list in the ControlPlane object, e.g. spec.kubeadmConfigSpec.files.
+	// Defaults to spec.files.
+	FilesPath string `json:"filesPath,omitempty"`
+	// FileOwner is the owner of the connect-agent static pod manifest. Defaults to root:root.
+	FileOwner string `json:"fileOwner,omitempty"`
+	// FilePermissions are the permissions of the connect-agent static pod manifest, e.g. "0600".
+	FilePermissions string `json:"filePermissions,omitempty"`
+	// LegacyManifestPath is the path of the connect-agent file injected into the ControlPlane objects of the clusters
+	// without a topology. Defaults to connect-agent.yaml, relative to the working directory of the bootstrap.
+	LegacyManifestPath string `json:"legacyManifestPath,omitempty"`
+}
+
+// FilesFields returns the fields of FilesPath.
+func (p Provider) FilesFields() []string {
+	return strings.Split(p.FilesPath, ".")
+}
+
+// withDefaults returns the provider with the default values set.
+func (p Provider) withDefaults() Provider {
+	if p.FilesPath == "" {
+		p.FilesPath = defaultFilesPath
+	}
+	if p.FileOwner == "" {
+		p.FileOwner = defaultFileOwner
+	}
+	return p
+}
+
+func (p Provider) validate() error {
+	if p.Kind == "" {
+		return fmt.Errorf("kind must not be empty")
+	}
+	if !path.IsAbs(p.StaticPodManifestPath) {
+		return fmt.Errorf("staticPodManifestPath of %s must be an absolute path", p.Kind)
+	}
+	for _, field := range p.FilesFields() {
+		if field == "" {
+			return fmt.Errorf("invalid filesPath %q of %s", p.FilesPath, p.Kind)
+		}
+	}
+	return nil
+}
+
+// Unknown returns the definition used for a ControlPlane API group and kind that no provider is defined for.
+// It has the default files path and owner, and no static pod manifest path.
+func Unknown(apiGroup, kind string) Provider {
+	return Provider{Kind: kind, APIGroup: apiGroup}.withDefaults()
+}
+
+// DefaultProviders are the control plane providers supported out of the box.
+var DefaultProviders = []Provider{
+	{
+		Kind:                  "RKE2ControlPlane",
+		APIGroup:              "controlplane.cluster.x-k8s.io",
+		StaticPodManifestPath: "/var/lib/rancher/rke2/agent/pod-manifests/connect-agent.yaml",
+		FilesPath:             "spec.files",
+	},
+	{
+		Kind:                  "KThreesControlPlane",
+		APIGroup:              "controlplane.cluster.x-k8s.io",
+		StaticPodManifestPath: "/var/lib/rancher/k3s/agent/pod-manifests/connect-agent.yaml",
+		FilesPath:             "spec.kthreesConfigSpec.files",
+	},
+	{
+		Kind:                  "KubeadmControlPlane",
+		APIGroup:              "controlplane.cluster.x-k8s.io",
+		StaticPodManifestPath: "/etc/kubernetes/manifests/connect-agent.yaml",
+		FilesPath:             "spec.kubeadmConfigSpec.files",
+		// The kubeadm bootstrap would write a relative path out of the static pod manifests directory of the kubelet.
+		LegacyManifestPath: "/etc/kubernetes/manifests/connect-agent.yaml",
+	},
+}
+
+// ParseProviders parses a YAML list of provider definitions, as found in the ConfigMap data.
+func ParseProviders(data []byte) ([]Provider, error) {
+	providers := []Provider{}
+	if err := yaml.UnmarshalStrict(data, &providers); err != nil {
+		return nil, fmt.Errorf("failed to parse providers: %w", err)
+	}
+	for i := range providers {
+		providers[i] = providers[i].withDefaults()
+		if err := providers[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+	return providers, nil
+}
+
 // ProviderManager is an interface that defines methods for managing CAPI control plane providers.
 //
 //go:generate mockery --name ProviderManager --filename provider_manager_mock.go --structname MockProviderManager --output ./mocks
 type ProviderManager interface {
-	// Register adds a static pod manifest path for a specific kind to the manager.
-	Register(kind string, path string)
-	StaticPodManifestPath(kind string) string
+	// Register adds or replaces a built-in provider definition in the manager.
+	Register(provider Provider)
+	// Provider returns the definition of the provider of a given ControlPlane API group and kind, if known.
+	Provider(apiGroup, kind string) (Provider, bool)
 }
 
 // ProviderManagerBuilder is a builder for ProviderManager.
 type ProviderManagerBuilder struct {
 	manager *manager
+
+	clientset kubernetes.Interface
+	namespace string
+	name      string
 }
 
 // NewProviderManagerBuilder creates a new builder for ProviderManager.
 func NewProviderManager() *ProviderManagerBuilder {
 	return &ProviderManagerBuilder{
 		manager: &manager{
-			pathMap: make(map[string]string),
+			defaults:  make(map[string]Provider),
+			providers: make(map[string]Provider),
 		},
 	}
 }
 
-// WithInjectStaticPodManifest adds an static pod manifest path for a specific kind to the manager.
-func (b *ProviderManagerBuilder) WithProvider(kind string, path string) *ProviderManagerBuilder {
-	b.manager.pathMap[kind] = path
+// WithProvider adds a built-in provider definition to the manager.
+func (b *ProviderManagerBuilder) WithProvider(provider Provider) *ProviderManagerBuilder {
+	b.manager.defaults[provider.Kind] = provider.withDefaults()
+	return b
+}
+
+// WithConfigMap loads additional provider definitions from a given ConfigMap, which take precedence over the
+// built-in ones, and reloads them whenever the ConfigMap changes.
+func (b *ProviderManagerBuilder) WithConfigMap(clientset kubernetes.Interface, namespace, name string) *ProviderManagerBuilder {
+	b.clientset = clientset
+	b.namespace = namespace
+	b.name = name
 	return b
 }
 
 // Build returns the constructed ProviderManager.
-func (b *ProviderManagerBuilder) Build() ProviderManager {
-	return b.manager
+// If a ConfigMap is given, it watches the ConfigMap until a given context is done and waits for the initial load.
+func (b *ProviderManagerBuilder) Build(ctx context.Context) (ProviderManager, error) {
+	b.manager.reset()
+	if b.clientset == nil {
+		return b.manager, nil
+	}
+
+	if err := b.manager.watchConfigMap(ctx, b.clientset, b.namespace, b.name); err != nil {
+		return nil, err
+	}
+	return b.manager, nil
 }
 
 type manager struct {
-	pathMap map[string]string
+	mu        sync.RWMutex
+	defaults  map[string]Provider
+	providers map[string]Provider
+}
+
+func (m *manager) Register(provider Provider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaults[provider.Kind] = provider.withDefaults()
+	m.providers[provider.Kind] = provider.withDefaults()
+}
+
+func (m *manager) Provider(apiGroup, kind string) (Provider, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	provider, ok := m.providers[kind]
+	if !ok || (provider.APIGroup != "" && provider.APIGroup != apiGroup) {
+		return Provider{}, false
+	}
+	return provider, true
+}
+
+// reset replaces the provider definitions with the built-in ones.
+func (m *manager) reset() {
+	m.load(nil)
 }
 
-func (m *manager) Register(kind string, path string) {
-	m.pathMap[kind] = path
+// load replaces the provider definitions with the built-in ones, overridden by given providers.
+func (m *manager) load(providers []Provider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.providers = make(map[string]Provider, len(m.defaults)+len(providers))
+	for kind, provider := range m.defaults {
+		m.providers[kind] = provider
+	}
+	for _, provider := range providers {
+		m.providers[provider.Kind] = provider
+	}
 }
 
-func (m *manager) StaticPodManifestPath(kind string) string {
-	path, ok := m.pathMap[kind]
-	if !ok {
-		return ""
+// watchConfigMap starts a ConfigMap informer scoped to a given ConfigMap and waits for its initial sync.
+func (m *manager) watchConfigMap(ctx context.Context, clientset kubernetes.Interface, namespace, name string) error {
+	log := log.FromContext(ctx).WithValues("configMap", namespace+"/"+name)
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+
+	reload := func(obj interface{}) {
+		configMap, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			return
+		}
+		providers, err := ParseProviders([]byte(configMap.Data[ProvidersKey]))
+		if err != nil {
+			// Keep the current definitions, so that a broken ConfigMap doesn't break the connect-agent injection.
+			log.Error(err, "Ignoring invalid control plane provider definitions")
+			return
+		}
+		m.load(providers)
+		log.Info("Loaded control plane provider definitions", "providers", len(providers))
 	}
 
-	return path
+	informer := factory.Core().V1().ConfigMaps()
+	_, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: reload,
+		UpdateFunc: func(_, obj interface{}) {
+			reload(obj)
+		},
+		DeleteFunc: func(_ interface{}) {
+			m.reset()
+			log.Info("Reset control plane provider definitions to the built-in ones")
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add ConfigMap event handler: %w", err)
+	}
+
+	factory.Start(ctx.Done())
+	for informerType, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync informer cache for %v", informerType)
+		}
+	}
+
+	// Load the definitions synchronously, as the event handlers may not have been called yet.
+	if configMap, err := informer.Lister().ConfigMaps(namespace).Get(name); err == nil {
+		reload(configMap)
+	}
+	return nil
 }
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProviders = `
+- kind: MyControlPlane
+  apiGroup: controlplane.example.com
+  staticPodManifestPath: /etc/my/manifests/connect-agent.yaml
+  filesPath: spec.config.files
+  filePermissions: "0600"
+- kind: RKE2ControlPlane
+  apiGroup: controlplane.cluster.x-k8s.io
+  staticPodManifestPath: /opt/rke2/pod-manifests/connect-agent.yaml
+`
+
+func TestParseProviders(t *testing.T) {
+	providers, err := ParseProviders([]byte(testProviders))
+	require.NoError(t, err)
+	require.Len(t, providers, 2)
+
+	assert.Equal(t, []string{"spec", "config", "files"}, providers[0].FilesFields())
+	assert.Equal(t, "root:root", providers[0].FileOwner)
+	assert.Equal(t, "0600", providers[0].FilePermissions)
+	assert.Equal(t, []string{"spec", "files"}, providers[1].FilesFields())
+
+	providers, err = ParseProviders(nil)
+	require.NoError(t, err)
+	assert.Empty(t, providers)
+
+	for _, invalid := range []string{
+		"- kind: MyControlPlane\n  staticPodManifestPath: connect-agent.yaml",
+		"- kind: MyControlPlane\n  staticPodManifestPath: /connect-agent.yaml\n  filesPath: spec..files",
+		"- staticPodManifestPath: /connect-agent.yaml",
+		"- kind: MyControlPlane\n  unknown: field",
+	} {
+		_, err := ParseProviders([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	m, err := NewProviderManager().WithProvider(DefaultProviders[0]).Build(context.TODO())
+	require.NoError(t, err)
+
+	p, ok := m.Provider("controlplane.cluster.x-k8s.io", "RKE2ControlPlane")
+	assert.True(t, ok)
+	assert.Equal(t, "/var/lib/rancher/rke2/agent/pod-manifests/connect-agent.yaml", p.StaticPodManifestPath)
+	assert.Equal(t, "root:root", p.FileOwner)
+	assert.Empty(t, p.LegacyManifestPath)
+
+	_, ok = m.Provider("controlplane.example.com", "RKE2ControlPlane")
+	assert.False(t, ok, "API group mismatch")
+	_, ok = m.Provider("controlplane.cluster.x-k8s.io", "KThreesControlPlane")
+	assert.False(t, ok, "unknown kind")
+
+	m.Register(Provider{Kind: "KThreesControlPlane", StaticPodManifestPath: "/k3s/connect-agent.yaml"})
+	p, ok = m.Provider("any.group", "KThreesControlPlane")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"spec", "files"}, p.FilesFields())
+}
+
+func TestProviderConfigMapReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test-ns",
+			Name:      "providers",
+		},
+		Data: map[string]string{ProvidersKey: testProviders},
+	}
+	clientset := fake.NewSimpleClientset(configMap)
+
+	m, err := NewProviderManager().
+		WithProvider(DefaultProviders[0]).
+		WithProvider(DefaultProviders[1]).
+		WithConfigMap(clientset, "test-ns", "providers").
+		Build(ctx)
+	require.NoError(t, err)
+
+	// The ConfigMap definitions are loaded on build and take precedence over the built-in ones.
+	p, ok := m.Provider("controlplane.example.com", "MyControlPlane")
+	assert.True(t, ok)
+	assert.Equal(t, "/etc/my/manifests/connect-agent.yaml", p.StaticPodManifestPath)
+	p, ok = m.Provider("controlplane.cluster.x-k8s.io", "RKE2ControlPlane")
+	assert.True(t, ok)
+	assert.Equal(t, "/opt/rke2/pod-manifests/connect-agent.yaml", p.StaticPodManifestPath)
+	_, ok = m.Provider("controlplane.cluster.x-k8s.io", "KThreesControlPlane")
+	assert.True(t, ok)
+
+	// Invalid definitions are ignored.
+	configMap.Data[ProvidersKey] = "- kind: BrokenControlPlane"
+	_, err = clientset.CoreV1().ConfigMaps("test-ns").Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	// Valid definitions are reloaded.
+	configMap.Data[ProvidersKey] = "- kind: OtherControlPlane\n  staticPodManifestPath: /other/connect-agent.yaml"
+	_, err = clientset.CoreV1().ConfigMaps("test-ns").Update(ctx, configMap, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, other := m.Provider("controlplane.example.com", "OtherControlPlane")
+		_, mine := m.Provider("controlplane.example.com", "MyControlPlane")
+		_, broken := m.Provider("", "BrokenControlPlane")
+		return other && !mine && !broken
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The built-in definitions are restored when the ConfigMap is deleted.
+	require.NoError(t, clientset.CoreV1().ConfigMaps("test-ns").Delete(ctx, "providers", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		p, ok := m.Provider("controlplane.cluster.x-k8s.io", "RKE2ControlPlane")
+		_, other := m.Provider("controlplane.example.com", "OtherControlPlane")
+		return ok && p.StaticPodManifestPath == DefaultProviders[0].StaticPodManifestPath && !other
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/pkg/gatewayclient"
+)
+
+const adminSessionReason = "closed by administrator"
+
+// initAdminRouter sets up the administrative API, if an admin token is configured.
+func (s *Server) initAdminRouter() {
+	if s.adminToken == "" {
+		return
+	}
+
+	a := s.router.PathPrefix(gatewayclient.AdminPathPrefix).Subrouter()
+	a.Use(s.adminAuthMiddleware)
+	a.HandleFunc("/tunnels", s.listTunnelsHandler).Methods(http.MethodGet)
+	a.HandleFunc("/tunnels/{tunnel_id}", s.getTunnelHandler).Methods(http.MethodGet)
+	a.HandleFunc("/tunnels/{tunnel_id}/sessions", s.disconnectHandler).Methods(http.MethodDelete)
+	a.HandleFunc("/tunnels/{tunnel_id}/client", s.flushClientHandler).Methods(http.MethodDelete)
+}
+
+// adminAuthMiddleware authenticates the requests to the administrative API with the admin bearer token.
+func (s *Server) adminAuthMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			log.Warnf("Unauthorized admin request %s %s from %s", req.Method, req.URL.Path, remoteAddress(req))
+			http.Error(rw, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
+func (s *Server) listTunnelsHandler(rw http.ResponseWriter, _ *http.Request) {
+	list := gatewayclient.TunnelList{
+		GatewayReplica: s.replicaName,
+		Tunnels:        []gatewayclient.Tunnel{},
+	}
+	for _, tunnel := range s.tunnels() {
+		list.Tunnels = append(list.Tunnels, *tunnel)
+	}
+	sort.Slice(list.Tunnels, func(i, j int) bool {
+		return list.Tunnels[i].TunnelID < list.Tunnels[j].TunnelID
+	})
+
+	writeJSON(rw, list)
+}
+
+func (s *Server) getTunnelHandler(rw http.ResponseWriter, req *http.Request) {
+	tunnelID := mux.Vars(req)["tunnel_id"]
+	tunnel, ok := s.tunnels()[tunnelID]
+	if !ok {
+		http.Error(rw, "tunnel not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(rw, tunnel)
+}
+
+func (s *Server) disconnectHandler(rw http.ResponseWriter, req *http.Request) {
+	tunnelID := mux.Vars(req)["tunnel_id"]
+	if _, ok := s.tunnels()[tunnelID]; !ok {
+		http.Error(rw, "tunnel not found", http.StatusNotFound)
+		return
+	}
+
+	reason := req.URL.Query().Get("reason")
+	if reason == "" {
+		reason = adminSessionReason
+	}
+	s.closeSessions(tunnelID, reason)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) flushClientHandler(rw http.ResponseWriter, req *http.Request) {
+	tunnelID := mux.Vars(req)["tunnel_id"]
+	s.flushClients(tunnelID)
+
+	if err := s.kubeclient.InvalidateCerts(tunnelID); err != nil {
+		log.Errorf("Failed to invalidate certs for tunnel %s: %v", tunnelID, err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := s.kubeclient.InvalidateKubeconfig(tunnelID); err != nil {
+		log.Errorf("Failed to invalidate kubeconfig for tunnel %s: %v", tunnelID, err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// tunnels returns the tunnels with active sessions on this replica, keyed by tunnel ID.
+func (s *Server) tunnels() map[string]*gatewayclient.Tunnel {
+	tunnels := map[string]*gatewayclient.Tunnel{}
+	s.sessions.Range(func(key, _ any) bool {
+		session := key.(*agentSession)
+		tunnel, ok := tunnels[session.tunnelID]
+		if !ok {
+			tunnel = &gatewayclient.Tunnel{TunnelID: session.tunnelID}
+			tunnels[session.tunnelID] = tunnel
+		}
+		tunnel.Sessions = append(tunnel.Sessions, gatewayclient.Session{
+			RemoteAddress:  session.info.RemoteAddress,
+			AgentVersion:   session.info.AgentVersion,
+			GatewayReplica: session.info.GatewayReplica,
+			ConnectedAt:    session.info.ConnectedAt,
+		})
+		return true
+	})
+
+	for _, tunnelID := range s.clients.tunnelIDs() {
+		if tunnel, ok := tunnels[tunnelID]; ok {
+			tunnel.CachedClients++
+		}
+	}
+	return tunnels
+}
+
+// flushClients removes the cached HTTP client of a given tunnel.
+func (s *Server) flushClients(tunnelID string) {
+	if s.clients.invalidate(tunnelID) {
+		log.Infof("Removed cached http client of tunnel %s", tunnelID)
+	}
+}
+
+func writeJSON(rw http.ResponseWriter, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Warnf("Failed to write response: %v", err)
+	}
+}
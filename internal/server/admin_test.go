@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/agent"
+	"github.com/open-edge-platform/cluster-connect-gateway/pkg/gatewayclient"
+)
+
+var _ = Describe("Admin API", func() {
+	const adminToken = "admin-token"
+
+	var (
+		kc      *fakeKubeclient
+		s       *Server
+		gateway *httptest.Server
+		client  *gatewayclient.Client
+		ctx     = context.Background()
+	)
+
+	BeforeEach(func() {
+		kc = &fakeKubeclient{}
+		authorizer := func(req *http.Request) (string, bool, error) {
+			return req.Header.Get(agent.TunnelIdHeader), true, nil
+		}
+
+		var err error
+		s, err = NewServer(
+			WithKubeClient(kc),
+			WithAuthorizer(authorizer, false),
+			WithReplicaName("gateway-0"),
+			WithAdminToken(adminToken),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		gateway = httptest.NewServer(s.router)
+
+		client, err = gatewayclient.New(gateway.URL, adminToken)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		gateway.Close()
+	})
+
+	dial := func(tunnelID string) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(gateway.URL, "http") + "/connect"
+		conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{
+			agent.TunnelIdHeader:     {tunnelID},
+			agent.AgentVersionHeader: {"v1.2.3"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return conn
+	}
+
+	It("should reject unauthenticated requests", func() {
+		unauthorized, err := gatewayclient.New(gateway.URL, "invalid")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = unauthorized.ListTunnels(ctx)
+		Expect(err).To(MatchError(ContainSubstring("401")))
+	})
+
+	It("should list and inspect the tunnels with active sessions", func() {
+		conn := dial("tunnel-b")
+		defer conn.Close()
+		conn = dial("tunnel-a")
+		defer conn.Close()
+		Eventually(kc.connectedSessions).Should(HaveLen(2))
+
+		list, err := client.ListTunnels(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.GatewayReplica).To(Equal("gateway-0"))
+		Expect(list.Tunnels).To(HaveLen(2))
+		Expect(list.Tunnels[0].TunnelID).To(Equal("tunnel-a"))
+		Expect(list.Tunnels[1].TunnelID).To(Equal("tunnel-b"))
+
+		tunnel, err := client.GetTunnel(ctx, "tunnel-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tunnel.Sessions).To(HaveLen(1))
+		Expect(tunnel.Sessions[0].AgentVersion).To(Equal("v1.2.3"))
+		Expect(tunnel.Sessions[0].GatewayReplica).To(Equal("gateway-0"))
+		Expect(tunnel.Sessions[0].ConnectedAt).NotTo(BeZero())
+
+		_, err = client.GetTunnel(ctx, "unknown")
+		Expect(err).To(MatchError(gatewayclient.ErrNotFound))
+	})
+
+	It("should force-disconnect the sessions of a tunnel", func() {
+		conn := dial("tunnel-a")
+		defer conn.Close()
+		Eventually(kc.connectedSessions).Should(HaveLen(1))
+
+		Expect(client.Disconnect(ctx, "tunnel-a", "maintenance")).To(Succeed())
+		Eventually(kc.disconnectReasons).Should(Equal([]string{"maintenance"}))
+
+		Expect(client.Disconnect(ctx, "tunnel-a", "")).To(MatchError(gatewayclient.ErrNotFound))
+	})
+
+	It("should flush the cached clients of a tunnel", func() {
+		s.clients.add("tunnel-a", &Client{})
+		s.clients.add("tunnel-b", &Client{})
+
+		Expect(client.FlushClient(ctx, "tunnel-a")).To(Succeed())
+		_, ok := s.clients.get("tunnel-a")
+		Expect(ok).To(BeFalse())
+		_, ok = s.clients.get("tunnel-b")
+		Expect(ok).To(BeTrue())
+	})
+})
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+)
+
+const (
+	defaultClientCacheSize        = 1000
+	defaultClientCacheIdleTimeout = 30 * time.Minute
+)
+
+// Reasons of the client cache evictions.
+const (
+	evictedSize         = "size"
+	evictedIdle         = "idle"
+	evictedInvalidated  = "invalidated"
+	evictedDisconnected = "disconnected"
+)
+
+// clientCache caches the HTTP client of each tunnel, keyed by tunnel ID, so that the requests to a tunnel share the
+// connections of its transport. It holds at most maxSize clients, evicting the least recently used one, and evicts
+// the clients unused for longer than idleTimeout.
+// A zero maxSize or idleTimeout disables the respective bound.
+type clientCache struct {
+	maxSize     int
+	idleTimeout time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru orders the entries from the most to the least recently used.
+	lru *list.List
+}
+
+type clientCacheEntry struct {
+	tunnelID string
+	client   *Client
+	lastUsed time.Time
+}
+
+func newClientCache(maxSize int, idleTimeout time.Duration) *clientCache {
+	return &clientCache{
+		maxSize:     maxSize,
+		idleTimeout: idleTimeout,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+	}
+}
+
+// get returns the cached client of a given tunnel, unless it has been idle for too long.
+func (c *clientCache) get(tunnelID string) (*Client, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	element, ok := c.entries[tunnelID]
+	if ok && c.expired(element.Value.(*clientCacheEntry), now) {
+		c.remove(element, evictedIdle)
+		ok = false
+	}
+	if !ok {
+		metrics.ClientCacheLookupsCounter.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+
+	metrics.ClientCacheLookupsCounter.WithLabelValues("hit").Inc()
+	entry := element.Value.(*clientCacheEntry)
+	entry.lastUsed = now
+	c.lru.MoveToFront(element)
+	return entry.client, true
+}
+
+// add caches the client of a given tunnel, evicting the least recently used clients over the size bound.
+func (c *clientCache) add(tunnelID string, client *Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[tunnelID]; ok {
+		c.remove(element, evictedInvalidated)
+	}
+	c.entries[tunnelID] = c.lru.PushFront(&clientCacheEntry{tunnelID: tunnelID, client: client, lastUsed: time.Now()})
+	for c.maxSize > 0 && c.lru.Len() > c.maxSize {
+		c.remove(c.lru.Back(), evictedSize)
+	}
+	metrics.ClientCacheSize.Set(float64(c.lru.Len()))
+}
+
+// invalidate evicts the client of a given tunnel and reports whether it was cached.
+func (c *clientCache) invalidate(tunnelID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[tunnelID]
+	if ok {
+		c.remove(element, evictedInvalidated)
+	}
+	return ok
+}
+
+// prune evicts the idle clients, and the clients of the tunnels for which keep returns false. It returns the tunnel
+// IDs of the evicted clients.
+func (c *clientCache) prune(keep func(tunnelID string) bool) []string {
+	c.mu.Lock()
+	now := time.Now()
+	var idle []string
+	for element := c.lru.Back(); element != nil; {
+		prev := element.Prev()
+		if entry := element.Value.(*clientCacheEntry); c.expired(entry, now) {
+			idle = append(idle, entry.tunnelID)
+			c.remove(element, evictedIdle)
+		}
+		element = prev
+	}
+
+	var disconnected []string
+	for tunnelID, element := range c.entries {
+		if !keep(tunnelID) {
+			disconnected = append(disconnected, tunnelID)
+			c.remove(element, evictedDisconnected)
+		}
+	}
+	c.mu.Unlock()
+	return append(idle, disconnected...)
+}
+
+// tunnelIDs returns the tunnel IDs of the cached clients.
+func (c *clientCache) tunnelIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tunnelIDs := make([]string, 0, len(c.entries))
+	for tunnelID := range c.entries {
+		tunnelIDs = append(tunnelIDs, tunnelID)
+	}
+	return tunnelIDs
+}
+
+func (c *clientCache) expired(entry *clientCacheEntry, now time.Time) bool {
+	return c.idleTimeout > 0 && now.Sub(entry.lastUsed) > c.idleTimeout
+}
+
+// remove evicts an element of the cache. The caller must hold the lock.
+func (c *clientCache) remove(element *list.Element, reason string) {
+	entry := c.lru.Remove(element).(*clientCacheEntry)
+	delete(c.entries, entry.tunnelID)
+	if entry.client != nil && entry.client.httpClient != nil {
+		entry.client.httpClient.CloseIdleConnections()
+	}
+	if entry.client != nil && entry.client.serviceTransport != nil {
+		entry.client.serviceTransport.CloseIdleConnections()
+	}
+	if entry.client != nil && entry.client.insecureServiceTransport != nil {
+		entry.client.insecureServiceTransport.CloseIdleConnections()
+	}
+	metrics.ClientCacheEvictionsCounter.WithLabelValues(reason).Inc()
+	metrics.ClientCacheSize.Set(float64(c.lru.Len()))
+}
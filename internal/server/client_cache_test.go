@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+)
+
+var _ = Describe("Client cache", func() {
+	evictions := func(reason string) float64 {
+		return testutil.ToFloat64(metrics.ClientCacheEvictionsCounter.WithLabelValues(reason))
+	}
+	cached := func(c *clientCache, tunnelID string) bool {
+		_, ok := c.get(tunnelID)
+		return ok
+	}
+
+	It("should evict the least recently used client over the size bound", func() {
+		c := newClientCache(2, 0)
+		before := evictions(evictedSize)
+
+		c.add("tunnel-a", &Client{})
+		c.add("tunnel-b", &Client{})
+		Expect(cached(c, "tunnel-a")).To(BeTrue())
+		c.add("tunnel-c", &Client{})
+
+		Expect(cached(c, "tunnel-a")).To(BeTrue())
+		Expect(cached(c, "tunnel-b")).To(BeFalse())
+		Expect(cached(c, "tunnel-c")).To(BeTrue())
+		Expect(evictions(evictedSize)).To(Equal(before + 1))
+	})
+
+	It("should evict the idle clients", func() {
+		c := newClientCache(0, 50*time.Millisecond)
+		before := evictions(evictedIdle)
+
+		c.add("tunnel-a", &Client{})
+		c.add("tunnel-b", &Client{})
+		time.Sleep(100 * time.Millisecond)
+		c.add("tunnel-c", &Client{})
+
+		Expect(c.prune(func(string) bool { return true })).To(ConsistOf("tunnel-a", "tunnel-b"))
+		Expect(c.tunnelIDs()).To(Equal([]string{"tunnel-c"}))
+		Expect(evictions(evictedIdle)).To(Equal(before + 2))
+
+		time.Sleep(100 * time.Millisecond)
+		Expect(cached(c, "tunnel-c")).To(BeFalse())
+	})
+
+	It("should evict the clients of the disconnected tunnels", func() {
+		c := newClientCache(0, 0)
+		c.add("tunnel-a", &Client{})
+		c.add("tunnel-b", &Client{})
+		c.add("tunnel-c", &Client{})
+
+		Expect(c.prune(func(tunnelID string) bool { return tunnelID == "tunnel-b" })).To(ConsistOf("tunnel-a", "tunnel-c"))
+		Expect(c.tunnelIDs()).To(Equal([]string{"tunnel-b"}))
+	})
+
+	It("should cache a single client per tunnel", func() {
+		c := newClientCache(0, 0)
+		first, second := &Client{}, &Client{}
+		c.add("tunnel-a", first)
+		c.add("tunnel-a", second)
+
+		client, ok := c.get("tunnel-a")
+		Expect(ok).To(BeTrue())
+		Expect(client).To(BeIdenticalTo(second))
+		Expect(c.tunnelIDs()).To(Equal([]string{"tunnel-a"}))
+	})
+
+	It("should evict the client of a tunnel once its ClusterConnect or kubeconfig changes", func() {
+		kc := &fakeKubeclient{}
+		s, err := NewServer(WithKubeClient(kc), WithReplicaName("gateway-0"))
+		Expect(err).NotTo(HaveOccurred())
+		before := evictions(evictedInvalidated)
+
+		s.clients.add("tunnel-a", &Client{})
+		s.clients.add("tunnel-b", &Client{})
+		kc.changeTunnel("tunnel-a", false)
+
+		Expect(s.clients.tunnelIDs()).To(Equal([]string{"tunnel-b"}))
+		Expect(evictions(evictedInvalidated)).To(Equal(before + 1))
+	})
+})
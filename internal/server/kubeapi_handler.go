@@ -4,40 +4,46 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/atomix/dazl"
 	"github.com/gorilla/mux"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/proxy"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/transport"
 
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/proxyutil"
 )
 
 const (
 	kubeApiEndpoint = "https://kubernetes.default.svc"
+	kubernetesRoute = "/{tunnel_id}/{kubernetes_uri:.*}"
 	UpgradeHeader   = "Upgrade"
 	SpdyPrefix      = "spdy/"
 	Websocket       = "websocket"
 )
 
 var (
-	log     = dazl.GetPackageLogger()
-	clients = sync.Map{}
-	er      = &errorResponder{}
+	log = dazl.GetPackageLogger()
+	er  = &errorResponder{}
 )
 
 type Client struct {
 	httpClient *http.Client
 	restCfg    *rest.Config
+	// serviceTransport dials the in-cluster services of the edge cluster, and insecureServiceTransport dials the
+	// https services whose certificate is not verified.
+	serviceTransport         *http.Transport
+	insecureServiceTransport *http.Transport
 }
 
 type LoggingTransport struct {
@@ -60,14 +66,28 @@ func (lt *LoggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 
 func (s *Server) KubeapiHandler(rw http.ResponseWriter, req *http.Request) {
 	start := time.Now()
-	timeout := req.URL.Query().Get("timeout")
-	if timeout == "" {
-		timeout = defaultTimeout
-	}
-
 	vars := mux.Vars(req)
 	tunnelID := vars["tunnel_id"]
 
+	// Record the metrics of every response, including the errors of the gateway.
+	labels := newProxyLabels(req, vars["kubernetes_uri"])
+	w := &proxyutil.ResponseRecorder{ResponseWriter: rw}
+	defer recordMetrics(&labels, w, start)
+	rw = w
+
+	// The requests share the transport of their tunnel, and are bounded by their own deadline instead.
+	timeout := requestTimeout(req, labels, s.requestTimeout)
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	if s.rejectSuspended(rw, tunnelID) {
+		return
+	}
+	labels.setTunnel(tunnelID)
+
 	// Parse the target URL
 	target, err := url.Parse(fmt.Sprintf("%s/%s", kubeApiEndpoint, vars["kubernetes_uri"]))
 	if err != nil {
@@ -77,7 +97,7 @@ func (s *Server) KubeapiHandler(rw http.ResponseWriter, req *http.Request) {
 	}
 	log.Debugf("[%s] REQ OK t=%s %+v", tunnelID, timeout, req)
 
-	client, cfg, err := s.GetClientFromKubeconfig(tunnelID, timeout)
+	client, cfg, err := s.GetClientFromKubeconfig(tunnelID)
 	if err != nil {
 		log.Errorf("Error getting client for tunnel %s: %s", tunnelID, err)
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -106,9 +126,26 @@ func (s *Server) KubeapiHandler(rw http.ResponseWriter, req *http.Request) {
 		http.Error(rw, "Unsupported Upgrade header", http.StatusBadRequest)
 		return
 	}
+}
 
-	// Record metrics
-	recordMetrics(rw, start)
+// rejectSuspended rejects the requests to a suspended or unknown tunnel, and reports whether it did.
+func (s *Server) rejectSuspended(rw http.ResponseWriter, tunnelID string) bool {
+	suspended, err := s.kubeclient.IsSuspended(tunnelID)
+	if apierrors.IsNotFound(err) {
+		http.Error(rw, fmt.Sprintf("cluster %s not found", tunnelID), http.StatusNotFound)
+		return true
+	}
+	if err != nil {
+		log.Errorf("Error checking suspension of tunnel %s: %s", tunnelID, err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return true
+	}
+	if suspended {
+		// Close the session in case the agent connected before the tunnel was suspended.
+		s.closeSessions(tunnelID, suspendedSessionReason)
+		http.Error(rw, fmt.Sprintf("access to cluster %s is suspended", tunnelID), http.StatusForbidden)
+	}
+	return suspended
 }
 
 func setRequestURL(req *http.Request, target *url.URL) {
@@ -117,17 +154,6 @@ func setRequestURL(req *http.Request, target *url.URL) {
 	req.URL.Path = target.Path
 }
 
-func recordMetrics(rw http.ResponseWriter, start time.Time) {
-	code := rw.Header().Get("Status")
-	if code == "" {
-		code = fmt.Sprintf("%d", http.StatusOK)
-	}
-	metrics.ProxiedHttpResponseCounter.WithLabelValues(code).Inc()
-
-	duration := time.Since(start).Seconds()
-	metrics.RequestLatency.Observe(duration)
-}
-
 func (s *Server) handleWebSocketOrHTTP(rw http.ResponseWriter, req *http.Request, target *url.URL, client *http.Client, tunnelID string) {
 	// Create proxy and set the transport to remotedialer client
 	proxyHandler := httputil.NewSingleHostReverseProxy(target)
@@ -158,17 +184,9 @@ func (s *Server) handleWebSocketOrHTTP(rw http.ResponseWriter, req *http.Request
 		log.Debugf("[%s] REQ DONE: %v", tunnelID, req)
 	}
 
-	proxyHandler.ModifyResponse = func(r *http.Response) error {
-		if r != nil {
-			code := fmt.Sprintf("%d", r.StatusCode)
-			metrics.ProxiedHttpResponseCounter.WithLabelValues(code).Inc()
-		}
-		return nil
-	}
-
 	proxyHandler.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		metrics.ProxiedHttpResponseCounter.WithLabelValues("502").Inc()
 		log.Debugf("[%s] REQ failed: %v", tunnelID, err)
+		w.WriteHeader(http.StatusBadGateway)
 	}
 
 	log.Debugf("[%s] REQ DONE: %+v", tunnelID, req)
@@ -182,6 +200,7 @@ func (s *Server) handleSPDY(rw http.ResponseWriter, req *http.Request, target *u
 
 	upgradeTransport, err := makeUpgradeTransport(cfg, client.Transport)
 	if err != nil {
+		er.Error(rw, req, err)
 		return
 	}
 	proxyHandler.UpgradeTransport = upgradeTransport
@@ -196,12 +215,21 @@ func (s *Server) handleSPDY(rw http.ResponseWriter, req *http.Request, target *u
 
 }
 
-func (s *Server) GetClientFromKubeconfig(tunnelID, timeout string) (*http.Client, *rest.Config, error) {
+// GetClientFromKubeconfig returns the HTTP client of a given tunnel, whose transport pools the connections to the
+// Kubernetes API of the edge cluster for all the requests to the tunnel.
+func (s *Server) GetClientFromKubeconfig(tunnelID string) (*http.Client, *rest.Config, error) {
+	client, err := s.getClient(tunnelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.httpClient, client.restCfg, nil
+}
+
+// getClient returns the cached client of a given tunnel, or creates it from the kubeconfig of the tunnel.
+func (s *Server) getClient(tunnelID string) (*Client, error) {
 	// Check if the client is already cached
-	key := fmt.Sprintf("%s/%s", tunnelID, timeout)
-	client, ok := clients.Load(key)
-	if ok {
-		return client.(*Client).httpClient, client.(*Client).restCfg, nil
+	if client, ok := s.clients.get(tunnelID); ok {
+		return client, nil
 	}
 
 	start := time.Now()
@@ -211,7 +239,7 @@ func (s *Server) GetClientFromKubeconfig(tunnelID, timeout string) (*http.Client
 	metrics.KubeconfigRetrievalDuration.Observe(duration)
 	if err != nil {
 		log.Errorf("Unable to get kubeconfig for %s: %v", tunnelID, err)
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Set the server URL to the default kubeapi service URL
@@ -224,13 +252,13 @@ func (s *Server) GetClientFromKubeconfig(tunnelID, timeout string) (*http.Client
 	bytesCfg, err := clientcmd.Write(*cfg)
 	if err != nil {
 		log.Errorf("Unable to write kubeconfig for %s: %v", tunnelID, err)
-		return nil, nil, err
+		return nil, err
 	}
 
 	restCfg, err := clientcmd.RESTConfigFromKubeConfig(bytesCfg)
 	if err != nil || restCfg == nil {
 		log.Errorf("Unable to create client config %s: %v", tunnelID, err)
-		return nil, nil, err
+		return nil, err
 	}
 
 	restCfg.Dial = s.remotedialer.Dialer(tunnelID)
@@ -238,15 +266,28 @@ func (s *Server) GetClientFromKubeconfig(tunnelID, timeout string) (*http.Client
 	httpClient, err := rest.HTTPClientFor(restCfg)
 	if err != nil {
 		log.Errorf("Unable to create HTTP client for %s: %v", tunnelID, err)
-		return nil, nil, err
+		return nil, err
+	}
+
+	serviceTransport, err := newServiceTransport(restCfg, false)
+	if err != nil {
+		log.Errorf("Unable to create service transport for %s: %v", tunnelID, err)
+		return nil, err
+	}
+	insecureServiceTransport, err := newServiceTransport(restCfg, true)
+	if err != nil {
+		log.Errorf("Unable to create service transport for %s: %v", tunnelID, err)
+		return nil, err
 	}
 
 	newClient := &Client{
-		httpClient: httpClient,
-		restCfg:    restCfg,
+		httpClient:               httpClient,
+		restCfg:                  restCfg,
+		serviceTransport:         serviceTransport,
+		insecureServiceTransport: insecureServiceTransport,
 	}
-	clients.Store(key, newClient)
-	return httpClient, restCfg, nil
+	s.clients.add(tunnelID, newClient)
+	return newClient, nil
 }
 
 func makeUpgradeTransport(config *rest.Config, rt http.RoundTripper) (proxy.UpgradeRequestRoundTripper, error) {
@@ -265,31 +306,36 @@ func makeUpgradeTransport(config *rest.Config, rt http.RoundTripper) (proxy.Upgr
 
 func (s *Server) cleanupUnusedHttpClients() {
 	log.Debug("cleaning unused http clients")
-	clients.Range(func(key, value any) bool {
-		clientName := key.(string)
-		// remove the timeout from the key to get the tunnel ID
-		tunnelId := strings.Split(clientName, "/")[0]
-		if !s.remotedialer.HasSession(tunnelId) {
-			log.Infof("session %s doesn't exist anymore, will proceed to remove client %s", tunnelId, clientName)
-			clients.Delete(clientName)
-			log.Info("finished removing unused http client")
-		}
-		return true
-	})
+	for _, tunnelID := range s.clients.prune(s.remotedialer.HasSession) {
+		log.Infof("removed idle or disconnected http client of tunnel %s", tunnelID)
+	}
 }
 
 func (s *Server) checkHttpClientsConnection() {
 	log.Debug("checking health of http clients")
-	clients.Range(func(key, value any) bool {
-		clientName := key.(string)
-		tunnelId := strings.Split(clientName, "/")[0]
-
-		log.Debugf("checking health of client %s for tunnel %s", clientName, tunnelId)
+	probed := map[string]bool{}
+	probe := func(tunnelId string) {
+		if probed[tunnelId] {
+			return
+		}
+		probed[tunnelId] = true
 		err := s.kubeclient.UpdateConnectionProbe(tunnelId, s.remotedialer.HasSession(tunnelId))
 		if err != nil {
 			log.Errorf("failed to update connection probe for tunnel %s: %v", tunnelId, err)
 		}
+	}
 
+	for _, tunnelId := range s.clients.tunnelIDs() {
+		log.Debugf("checking health of clients for tunnel %s", tunnelId)
+		probe(tunnelId)
+	}
+
+	// Probe the tunnels of the sessions held by this replica as well, so that the connection probe of
+	// idle tunnels, which have no http client, doesn't go stale.
+	s.sessions.Range(func(key, _ any) bool {
+		if tunnelId := key.(*agentSession).tunnelID; tunnelId != "" {
+			probe(tunnelId)
+		}
 		return true
 	})
 	log.Debug("finished checking health of http clients")
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/proxyutil"
+)
+
+// proxyLabels are the metric labels of a proxied Kubernetes API request.
+type proxyLabels struct {
+	tunnel  string
+	verb    string
+	group   string
+	upgrade string
+	// stream is set for the watches and the streams, whose duration is recorded apart from the request latency, and
+	// which have no request deadline.
+	stream bool
+}
+
+// newProxyLabels returns the metric labels of a request to the Kubernetes API path kubernetesURI of a tunnel. The
+// tunnel is labeled as the other tunnels until it is known to exist, see setTunnel.
+func newProxyLabels(req *http.Request, kubernetesURI string) proxyLabels {
+	labels := proxyLabels{
+		tunnel: metrics.OtherTunnels,
+		verb:   strings.ToLower(req.Method),
+	}
+
+	info, err := proxyutil.NewRequestInfo(proxyutil.KubernetesRequest(req, kubernetesURI))
+	if err == nil {
+		labels.verb = info.Verb
+		if info.IsResourceRequest {
+			labels.group = info.APIGroup
+			if labels.group == "" {
+				labels.group = "core"
+			}
+		}
+	}
+
+	// The upgraded requests are exec, attach and port-forward streams.
+	if upgrade := req.Header.Get(UpgradeHeader); upgrade != "" {
+		labels.upgrade = strings.ToLower(upgrade)
+		if info != nil && info.Subresource != "" {
+			labels.upgrade = info.Subresource
+		}
+	}
+	labels.stream = labels.upgrade != "" || labels.verb == "watch" || req.URL.Query().Get("follow") == "true"
+	return labels
+}
+
+// setTunnel labels the request with a given tunnel, once the tunnel is known to exist, so that the requests to made-up
+// tunnel IDs don't take the tunnel labels.
+func (l *proxyLabels) setTunnel(tunnelID string) {
+	l.tunnel = metrics.TunnelLabel(tunnelID)
+}
+
+// recordMetrics records the response code and the latency, or the stream duration, of a proxied request.
+func recordMetrics(labels *proxyLabels, w *proxyutil.ResponseRecorder, start time.Time) {
+	code := fmt.Sprintf("%d", w.StatusCode())
+	metrics.ProxiedHttpResponseCounter.WithLabelValues(code, labels.tunnel, labels.verb, labels.group, labels.upgrade).Inc()
+
+	duration := time.Since(start).Seconds()
+	if labels.stream {
+		metrics.StreamDuration.WithLabelValues(labels.tunnel, labels.verb, labels.group, labels.upgrade).Observe(duration)
+	} else {
+		metrics.RequestLatency.WithLabelValues(labels.tunnel, labels.verb, labels.group).Observe(duration)
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
+)
+
+var _ = Describe("Proxy metrics", func() {
+	DescribeTable("should label the Kubernetes API requests",
+		func(method, uri, upgrade string, expected proxyLabels) {
+			req := httptest.NewRequest(method, "/kubernetes/test-tunnel/"+uri, nil)
+			if upgrade != "" {
+				req.Header.Set(UpgradeHeader, upgrade)
+			}
+			expected.tunnel = metrics.OtherTunnels
+			kubernetesURI := strings.TrimPrefix(req.URL.Path, "/kubernetes/test-tunnel/")
+			Expect(newProxyLabels(req, kubernetesURI)).To(Equal(expected))
+		},
+		Entry("list", http.MethodGet, "api/v1/namespaces/default/pods", "",
+			proxyLabels{verb: "list", group: "core"}),
+		Entry("patch", http.MethodPatch, "apis/apps/v1/namespaces/default/deployments/nginx", "",
+			proxyLabels{verb: "patch", group: "apps"}),
+		Entry("non-resource", http.MethodGet, "version", "",
+			proxyLabels{verb: "get"}),
+		Entry("watch", http.MethodGet, "api/v1/pods?watch=true", "",
+			proxyLabels{verb: "watch", group: "core", stream: true}),
+		Entry("followed logs", http.MethodGet, "api/v1/namespaces/default/pods/nginx/log?follow=true", "",
+			proxyLabels{verb: "get", group: "core", stream: true}),
+		Entry("exec", http.MethodPost, "api/v1/namespaces/default/pods/nginx/exec?command=sh", "SPDY/3.1",
+			proxyLabels{verb: "create", group: "core", upgrade: "exec", stream: true}),
+		Entry("port-forward", http.MethodGet, "api/v1/namespaces/default/pods/nginx/portforward", "websocket",
+			proxyLabels{verb: "get", group: "core", upgrade: "portforward", stream: true}),
+	)
+
+	It("should count the responses of the gateway once", func() {
+		kc := &fakeKubeclient{suspended: true}
+		s, err := NewServer(WithKubeClient(kc), WithReplicaName("gateway-0"))
+		Expect(err).NotTo(HaveOccurred())
+		gateway := httptest.NewServer(s.router)
+		defer gateway.Close()
+
+		// The requests rejected before the tunnel is known to exist are labeled as the other tunnels.
+		counter := metrics.ProxiedHttpResponseCounter.WithLabelValues("403", metrics.OtherTunnels, "list", "core", "")
+		before := testutil.ToFloat64(counter)
+
+		resp, err := http.Get(gateway.URL + "/kubernetes/metrics-tunnel/api/v1/namespaces")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 1))
+	})
+
+	It("should not take a tunnel label for an unknown tunnel", func() {
+		clusterConnects := v1alpha1.GroupVersion.WithResource("clusterconnects").GroupResource()
+		kc := &fakeKubeclient{suspendedErr: apierrors.NewNotFound(clusterConnects, "unknown-tunnel")}
+		s, err := NewServer(WithKubeClient(kc), WithReplicaName("gateway-0"))
+		Expect(err).NotTo(HaveOccurred())
+		gateway := httptest.NewServer(s.router)
+		defer gateway.Close()
+
+		counter := metrics.ProxiedHttpResponseCounter.WithLabelValues("404", metrics.OtherTunnels, "list", "core", "")
+		before := testutil.ToFloat64(counter)
+
+		resp, err := http.Get(gateway.URL + "/kubernetes/unknown-tunnel/api/v1/namespaces")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		Expect(testutil.ToFloat64(counter)).To(Equal(before + 1))
+		Expect(testutil.ToFloat64(
+			metrics.ProxiedHttpResponseCounter.WithLabelValues("404", "unknown-tunnel", "list", "core", ""))).To(BeZero())
+		metrics.ReleaseTunnelLabel("unknown-tunnel")
+	})
+})
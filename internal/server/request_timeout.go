@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultRequestTimeout matches the default --request-timeout of the kube-apiserver.
+const defaultRequestTimeout = 60 * time.Second
+
+// requestTimeout returns the deadline of a proxied Kubernetes API request, or zero if it has none. The watches and
+// the streams, e.g. logs -f, exec and port-forward, last as long as the client wants. The other requests are bounded
+// by their timeout query parameter, as set by kubectl --request-timeout, up to a given maximum, which is also their
+// default, like the kube-apiserver does.
+func requestTimeout(req *http.Request, labels proxyLabels, maxTimeout time.Duration) time.Duration {
+	if labels.stream {
+		return 0
+	}
+	if timeout, ok := parseTimeout(req.URL.Query().Get("timeout")); ok && (maxTimeout <= 0 || timeout < maxTimeout) {
+		return timeout
+	}
+	return maxTimeout
+}
+
+// parseTimeout parses the timeout query parameter, either a duration or a number of seconds.
+func parseTimeout(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			log.Debugf("Ignoring invalid timeout %q: %v", value, err)
+			return 0, false
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+	return timeout, timeout > 0
+}
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request timeout", func() {
+	DescribeTable("should bound the requests but the watches and streams",
+		func(target string, upgrade string, maxTimeout, expected time.Duration) {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			if upgrade != "" {
+				req.Header.Set(UpgradeHeader, upgrade)
+			}
+			labels := newProxyLabels(req, strings.TrimPrefix(req.URL.Path, "/kubernetes/test-tunnel/"))
+			Expect(requestTimeout(req, labels, maxTimeout)).To(Equal(expected))
+		},
+		Entry("get", "/kubernetes/test-tunnel/api/v1/namespaces/default/pods/p", "", time.Minute, time.Minute),
+		Entry("list with a shorter timeout", "/kubernetes/test-tunnel/api/v1/pods?timeout=32s", "", time.Minute, 32*time.Second),
+		Entry("list with a timeout in seconds", "/kubernetes/test-tunnel/api/v1/pods?timeout=15", "", time.Minute, 15*time.Second),
+		Entry("list with a longer timeout", "/kubernetes/test-tunnel/api/v1/pods?timeout=5m", "", time.Minute, time.Minute),
+		Entry("list with an invalid timeout", "/kubernetes/test-tunnel/api/v1/pods?timeout=soon", "", time.Minute, time.Minute),
+		Entry("list without a maximum", "/kubernetes/test-tunnel/api/v1/pods?timeout=5m", "", time.Duration(0), 5*time.Minute),
+		Entry("watch", "/kubernetes/test-tunnel/api/v1/pods?watch=true&timeout=15", "", time.Minute, time.Duration(0)),
+		Entry("follow logs", "/kubernetes/test-tunnel/api/v1/namespaces/default/pods/p/log?follow=true", "", time.Minute, time.Duration(0)),
+		Entry("exec", "/kubernetes/test-tunnel/api/v1/namespaces/default/pods/p/exec?command=sh", "SPDY/3.1", time.Minute, time.Duration(0)),
+	)
+})
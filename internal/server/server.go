@@ -4,17 +4,25 @@
 package server
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"net/http"
+	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/atomix/dazl"
 	_ "github.com/atomix/dazl/zap"
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rancher/remotedialer"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/audit"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/metrics"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/middleware"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/opa"
@@ -23,12 +31,16 @@ import (
 )
 
 const (
-	defaultTimeout   = "15"
-	maxBodySizeLimit = 100 // mega-bytes
+	maxBodySizeLimit       = 100 // mega-bytes
+	defaultShutdownTimeout = 20 * time.Second
+	// sessionCloseTimeout is the time given to the closed agent sessions to record their end on shutdown.
+	sessionCloseTimeout  = 5 * time.Second
+	shutdownPollInterval = 100 * time.Millisecond
 )
 
 type Server struct {
 	router                 *mux.Router
+	tlsRouter              *mux.Router
 	remotedialer           *remotedialer.Server
 	listenAddr             string
 	enableAuth             bool
@@ -44,6 +56,31 @@ type Server struct {
 	opaPort                int
 	cleanupTicker          *time.Ticker
 	connectionProbeTicker  *time.Ticker
+	replicaName            string
+	adminToken             string
+	shutdownTimeout        time.Duration
+	tlsListenAddr          string
+	tlsCertFile            string
+	tlsKeyFile             string
+	agentClientCAFile      string
+	rateLimiter            *middleware.RateLimiter
+	auditor                *audit.Auditor
+	impersonation          *middleware.Impersonation
+	clientCacheSize        int
+	clientCacheIdleTimeout time.Duration
+	requestTimeout         time.Duration
+	clients                *clientCache
+	certWatcher            *certwatcher.CertWatcher
+	httpServers            []*http.Server
+
+	// sessions holds the active agent sessions of this replica, keyed by *agentSession.
+	sessions sync.Map
+	// activeSessions counts the agent sessions whose end is not recorded yet.
+	activeSessions atomic.Int64
+
+	// draining is set on shutdown to reject new /kubernetes requests while the in-flight ones complete.
+	draining         atomic.Bool
+	inflightRequests atomic.Int64
 }
 
 type ServerOptions func(*Server)
@@ -131,13 +168,79 @@ func WithTLSInsecureSkipVerify(insecureSkipVerify bool) ServerOptions {
 	}
 }
 
+// WithReplicaName sets the name of this gateway replica, which is recorded in the ClusterConnect session status.
+func WithReplicaName(name string) ServerOptions {
+	return func(s *Server) {
+		s.replicaName = name
+	}
+}
+
+// WithAdminToken enables the administrative API, which is authenticated with a given bearer token.
+func WithAdminToken(token string) ServerOptions {
+	return func(s *Server) {
+		s.adminToken = token
+	}
+}
+
+// WithRateLimits limits the /kubernetes and /services requests per tunnel ID and per JWT subject.
+func WithRateLimits(tunnel, user middleware.RateLimit) ServerOptions {
+	return func(s *Server) {
+		s.rateLimiter = middleware.NewRateLimiter(tunnel, user)
+	}
+}
+
+// WithClientCache bounds the cache of the tunnel HTTP clients to a given number of clients, and evicts the
+// clients unused for a given idle timeout. A zero size or idle timeout disables the respective bound.
+func WithClientCache(size int, idleTimeout time.Duration) ServerOptions {
+	return func(s *Server) {
+		s.clientCacheSize = size
+		s.clientCacheIdleTimeout = idleTimeout
+	}
+}
+
+// WithRequestTimeout sets the maximum and default deadline of the proxied Kubernetes API requests, except the
+// watches and streams. A zero timeout only applies the timeouts requested by the clients.
+func WithRequestTimeout(timeout time.Duration) ServerOptions {
+	return func(s *Server) {
+		s.requestTimeout = timeout
+	}
+}
+
+// WithImpersonation impersonates the users authenticated with a JWT on the edge clusters, if enabled.
+func WithImpersonation(enabled bool, impersonation middleware.Impersonation) ServerOptions {
+	return func(s *Server) {
+		if enabled {
+			s.impersonation = &impersonation
+		}
+	}
+}
+
+// WithAuditor records the /kubernetes and /services requests with a given auditor.
+func WithAuditor(auditor *audit.Auditor) ServerOptions {
+	return func(s *Server) {
+		s.auditor = auditor
+	}
+}
+
+// WithShutdownTimeout sets the deadline for the in-flight /kubernetes requests to complete on shutdown,
+// before the agent sessions are closed.
+func WithShutdownTimeout(timeout time.Duration) ServerOptions {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
 // Build creates a new Server with the configured options
 func NewServer(options ...ServerOptions) (s *Server, err error) {
 	server := &Server{
-		listenAddr:  "0.0.0.0:8080",
-		enableAuth:  false,
-		authorizer:  nil,
-		errorWriter: remotedialer.DefaultErrorWriter,
+		listenAddr:             "0.0.0.0:8080",
+		enableAuth:             false,
+		authorizer:             nil,
+		errorWriter:            remotedialer.DefaultErrorWriter,
+		shutdownTimeout:        defaultShutdownTimeout,
+		clientCacheSize:        defaultClientCacheSize,
+		clientCacheIdleTimeout: defaultClientCacheIdleTimeout,
+		requestTimeout:         defaultRequestTimeout,
 	}
 
 	for _, option := range options {
@@ -146,46 +249,227 @@ func NewServer(options ...ServerOptions) (s *Server, err error) {
 
 	// Set certManager to a new in-cluster cert manager if not provided
 	if server.kubeclient == nil {
-		server.kubeclient, err = kubeutil.NewInClusterClient()
+		server.kubeclient, err = kubeutil.NewInClusterClient(context.Background())
 		if err != nil {
 			log.Fatalf("Failed to create cert manager: %v", err)
 		}
 	}
 
-	server.remotedialer = remotedialer.New(server.authorizer, server.errorWriter)
+	// Evict the cached client of a tunnel once its ClusterConnect or kubeconfig changes, and release its metrics label
+	// once its ClusterConnect is deleted.
+	server.clients = newClientCache(server.clientCacheSize, server.clientCacheIdleTimeout)
+	server.kubeclient.OnTunnelChange(func(tunnelID string, deleted bool) {
+		if server.clients.invalidate(tunnelID) {
+			log.Infof("Removed cached http client of changed tunnel %s", tunnelID)
+		}
+		if deleted {
+			metrics.ReleaseTunnelLabel(tunnelID)
+		}
+	})
+
+	// Default the replica name to the hostname, which is the Pod name in Kubernetes.
+	if server.replicaName == "" {
+		server.replicaName, err = os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := server.initTLS(); err != nil {
+		return nil, err
+	}
+
+	if server.impersonation != nil && !server.enableAuth {
+		return nil, errors.New("impersonation requires JWT authentication")
+	}
+
+	server.remotedialer = remotedialer.New(
+		trackSessionAuthorizer(server.suspensionAuthorizer(server.agentClientCertAuthorizer(server.authorizer))),
+		suspensionErrorWriter(server.errorWriter),
+	)
 	server.router = mux.NewRouter()
+	server.tlsRouter = mux.NewRouter()
 	server.initRouter()
 
 	return server, nil
 }
 
-func (s *Server) Run() error {
+// Run serves the gateway until a given context is done, then shuts it down gracefully.
+func (s *Server) Run(ctx context.Context) error {
+	loopCtx, stopLoops := context.WithCancel(ctx)
+	var loops sync.WaitGroup
+	defer loops.Wait()
+	defer stopLoops()
+
 	if s.cleanupTicker != nil {
-		go func() {
+		loops.Go(func() {
 			log.Debug("starting routine to clean-up unused http clients")
-			for range s.cleanupTicker.C {
-				s.cleanupUnusedHttpClients()
+			for {
+				select {
+				case <-loopCtx.Done():
+					return
+				case <-s.cleanupTicker.C:
+					s.cleanupUnusedHttpClients()
+				}
 			}
-		}()
+		})
 	}
 
 	if s.connectionProbeTicker != nil {
-		go func() {
+		loops.Go(func() {
 			log.Debug("starting routine to check connection of http clients")
-			for range s.connectionProbeTicker.C {
-				s.checkHttpClientsConnection()
+			for {
+				select {
+				case <-loopCtx.Done():
+					return
+				case <-s.connectionProbeTicker.C:
+					s.checkHttpClientsConnection()
+					s.closeSuspendedSessions()
+				}
+			}
+		})
+	}
+
+	errChan := make(chan error, 2)
+	httpServer := &http.Server{
+		Addr:    s.listenAddr,
+		Handler: s.router,
+	}
+	s.httpServers = append(s.httpServers, httpServer)
+	go func() {
+		log.Infof("Listening on %s", s.listenAddr)
+		errChan <- httpServer.ListenAndServe()
+	}()
+
+	if s.certWatcher != nil {
+		loops.Go(func() {
+			if err := s.certWatcher.Start(loopCtx); err != nil {
+				log.Errorf("Failed to watch the TLS certificate: %v", err)
 			}
+		})
+
+		tlsServer := s.newTLSServer()
+		s.httpServers = append(s.httpServers, tlsServer)
+		go func() {
+			log.Infof("Listening with TLS on %s", s.tlsListenAddr)
+			errChan <- tlsServer.ListenAndServeTLS("", "")
 		}()
 	}
 
-	log.Infof("Listening on %s", s.listenAddr)
-	if err := http.ListenAndServe(s.listenAddr, s.router); err != nil {
+	select {
+	case err := <-errChan:
 		return err
+	case <-ctx.Done():
+	}
+
+	// Stop the loops first, so that no probe or suspension check races with the shutdown.
+	stopLoops()
+	loops.Wait()
+	return s.shutdown()
+}
+
+// shutdown stops accepting new connections and /kubernetes requests, drains the in-flight requests up to
+// the shutdown timeout, then closes the agent sessions.
+func (s *Server) shutdown() error {
+	log.Infof("Shutting down, draining in-flight requests for up to %s", s.shutdownTimeout)
+	s.draining.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	// Shutdown closes the listener and waits for the in-flight requests, except for the upgraded ones
+	// (kubectl exec, attach and port-forward, and the agent sessions) which are hijacked.
+	shutdownErrs := make([]error, len(s.httpServers))
+	var shutdowns sync.WaitGroup
+	for i, httpServer := range s.httpServers {
+		shutdowns.Go(func() {
+			shutdownErrs[i] = httpServer.Shutdown(ctx)
+		})
+	}
+
+	// The /kubernetes handlers of the upgraded requests return once the proxied connection is closed.
+	if !waitUntilZero(ctx, &s.inflightRequests) {
+		log.Warnf("Shutdown timeout exceeded, aborting %d in-flight requests", s.inflightRequests.Load())
+	}
+
+	// The in-flight requests are done, or aborted below, so the tunnels are no longer needed.
+	s.closeAllSessions(websocket.CloseGoingAway, "gateway shutting down")
+	sessionCtx, sessionCancel := context.WithTimeout(context.Background(), sessionCloseTimeout)
+	defer sessionCancel()
+	if !waitUntilZero(sessionCtx, &s.activeSessions) {
+		log.Warnf("Failed to record the end of %d sessions", s.activeSessions.Load())
 	}
 
+	shutdowns.Wait()
+	var errs []error
+	for i, err := range shutdownErrs {
+		if err != nil {
+			log.Warnf("Closing the remaining connections of %s: %v", s.httpServers[i].Addr, err)
+			errs = append(errs, s.httpServers[i].Close())
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	log.Info("Shutdown complete")
 	return nil
 }
 
+// waitUntilZero waits until a given counter is zero or a given context is done,
+// and reports whether the counter reached zero.
+func waitUntilZero(ctx context.Context, counter *atomic.Int64) bool {
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for counter.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
+// useTunnelMiddlewares applies the middlewares of the /kubernetes and /services endpoints to a given subrouter. The
+// external endpoints limit the size of the requests, strip their impersonation headers, and authenticate them with a
+// given middleware, if enabled. The requests are audited before the authentication, so that the rejected requests
+// are audited as well.
+func (s *Server) useTunnelMiddlewares(r *mux.Router, external bool, auth mux.MiddlewareFunc, impersonate bool) {
+	r.Use(s.drainMiddleware)
+	if external {
+		r.Use(middleware.SizeLimitMiddleware(maxBodySizeLimit * 1024 * 1024)) // 100 MB
+		r.Use(middleware.StripImpersonationMiddleware)
+	}
+	if s.auditor != nil && s.auditor.Enabled() {
+		r.Use(s.auditor.Middleware)
+	}
+	if auth != nil {
+		r.Use(auth)
+	}
+	if impersonate && s.impersonation != nil {
+		r.Use(s.impersonation.Middleware)
+	}
+	if s.rateLimiter != nil && s.rateLimiter.Enabled() {
+		r.Use(s.rateLimiter.Middleware)
+	}
+}
+
+// drainMiddleware tracks the in-flight requests and rejects the new ones once the server is shutting down.
+func (s *Server) drainMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		s.inflightRequests.Add(1)
+		defer s.inflightRequests.Add(-1)
+
+		if s.draining.Load() {
+			rw.Header().Set("Connection", "close")
+			rw.Header().Set("Retry-After", "1")
+			http.Error(rw, "gateway is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
+
 // This function doesn't work properly with remote kubeapi, getting 403 error
 // TODO: fix this later and use instead of GetClientFromKubeconfig
 func (s *Server) GetClient(tunnelID string, timeout string) (*http.Client, error) {
@@ -243,11 +527,7 @@ func (s *Server) GetClient(tunnelID string, timeout string) (*http.Client, error
 
 func (s *Server) initRouter() {
 	// healthz endpoint that simply returns "Ok" to indicate that the server is running
-	s.router.HandleFunc("/healthz", func(rw http.ResponseWriter, req *http.Request) {
-		if _, err := rw.Write([]byte("Ok\n")); err != nil {
-			return
-		}
-	}).Methods("GET")
+	s.router.HandleFunc("/healthz", healthzHandler).Methods("GET")
 
 	// metrics endpoint that exposes the prometheus metrics
 	if s.enableMetrics {
@@ -255,26 +535,70 @@ func (s *Server) initRouter() {
 	}
 
 	// connect endpoint that handles the tunnel connection requests from agents
-	s.router.Handle("/connect", s.remotedialer)
-
-	// Setup a subrouter for the external /kubernetes endpoint
-	// This subrouter will handle requests to /kubernetes/{tunnel_id}/* from outside the cluster
-	// It should perform JWT authorization if enabled
-	k := s.router.Host(s.externalHost).PathPrefix("/kubernetes").Subrouter()
-	k.HandleFunc("/{tunnel_id}/{kubernetes_uri:.*}", s.KubeapiHandler)
-	k.Use(middleware.SizeLimitMiddleware(maxBodySizeLimit * 1024 * 1024)) // 100 MB
+	s.router.HandleFunc("/connect", s.ConnectHandler)
+
+	var authMiddleware mux.MiddlewareFunc
 	if s.enableAuth {
 		opaClient := opa.NewOPAClient(opa.OpaConfig{OpaAddress: s.opaAddress, OpaPort: s.opaPort})
 		jAuthorization := middleware.JwtAuthorization{
 			JwtAuthenticator: &orchlibraryauth.JwtAuthenticator{}, OpaClient: opaClient, RbacEnabled: true}
-		k.Use(jAuthorization.AuthMiddleware)
+		authMiddleware = jAuthorization.AuthMiddleware
 	}
 
+	// Setup the subrouters for the external /kubernetes and /services endpoints
+	// These subrouters will handle requests to /kubernetes/{tunnel_id}/* and
+	// /services/{tunnel_id}/{namespace}/{service}:{port}/* from outside the cluster
+	// They should perform JWT authorization if enabled
+	s.handleExternalRoutes(
+		s.router.Host(s.externalHost).PathPrefix("/kubernetes").Subrouter(),
+		s.router.Host(s.externalHost).PathPrefix("/services").Subrouter(),
+		authMiddleware,
+	)
+
 	// Setup a subrouter for the internal /kubernetes endpoint
 	// This subrouter will handle requests to /kubernetes/{tunnel_id}/* from within the cluster
 	// No JWT authorization is required
-	k = s.router.PathPrefix("/kubernetes").Subrouter()
-	k.HandleFunc("/{tunnel_id}/{kubernetes_uri:.*}", s.KubeapiHandler)
+	k := s.router.PathPrefix("/kubernetes").Subrouter()
+	k.HandleFunc(kubernetesRoute, s.KubeapiHandler)
+	s.useTunnelMiddlewares(k, false, nil, false)
+
+	// Setup a subrouter for the internal /services endpoint
+	// This subrouter will handle requests to /services/{tunnel_id}/{namespace}/{service}:{port}/* from within the
+	// cluster
+	k = s.router.PathPrefix("/services").Subrouter()
+	s.handleServices(k)
+	s.useTunnelMiddlewares(k, false, nil, false)
+
+	// admin endpoints that manage the sessions of this replica
+	s.initAdminRouter()
+
+	// The TLS listener is reachable from outside the cluster without ingress. It serves only the agent connections
+	// and the external endpoints, whatever the Host of the request, and neither the internal nor the admin endpoints.
+	if s.certWatcher != nil {
+		s.tlsRouter.HandleFunc("/healthz", healthzHandler).Methods("GET")
+		s.tlsRouter.HandleFunc("/connect", s.ConnectHandler)
+		s.handleExternalRoutes(
+			s.tlsRouter.PathPrefix("/kubernetes").Subrouter(),
+			s.tlsRouter.PathPrefix("/services").Subrouter(),
+			authMiddleware,
+		)
+	}
 
 	// Add more endpoints and handlers as needed
 }
+
+// handleExternalRoutes registers the external /kubernetes and /services endpoints on given subrouters. Only the
+// Kubernetes API supports impersonation.
+func (s *Server) handleExternalRoutes(kubernetes, services *mux.Router, auth mux.MiddlewareFunc) {
+	kubernetes.HandleFunc(kubernetesRoute, s.KubeapiHandler)
+	s.useTunnelMiddlewares(kubernetes, true, auth, true)
+
+	s.handleServices(services)
+	s.useTunnelMiddlewares(services, true, auth, false)
+}
+
+func healthzHandler(rw http.ResponseWriter, _ *http.Request) {
+	if _, err := rw.Write([]byte("Ok\n")); err != nil {
+		return
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/middleware"
 )
 
 func TestServer(t *testing.T) {
@@ -36,4 +38,15 @@ var _ = Describe("Server", func() {
 	//		})
 	//	})
 	//})
+
+	It("should require JWT authentication to impersonate the users", func() {
+		_, err := NewServer(WithKubeClient(&fakeKubeclient{}), WithReplicaName("gateway-0"),
+			WithImpersonation(true, middleware.Impersonation{}))
+		Expect(err).To(MatchError(ContainSubstring("requires JWT authentication")))
+
+		s, err := NewServer(WithKubeClient(&fakeKubeclient{}), WithReplicaName("gateway-0"),
+			WithImpersonation(false, middleware.Impersonation{}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.impersonation).To(BeNil())
+	})
 })
@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"k8s.io/client-go/rest"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/middleware"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/proxyutil"
+)
+
+const (
+	serviceRoute = "/{tunnel_id}/{namespace}/{service}:{port:[0-9]+}"
+	// serviceIdleConnTimeout is the time after which an idle connection to an in-cluster service is closed.
+	serviceIdleConnTimeout = 90 * time.Second
+)
+
+// handleServices registers the /services routes on a given subrouter. The requests to a service without a trailing
+// slash are redirected, so that the relative links of its web UI resolve below the service prefix.
+func (s *Server) handleServices(r *mux.Router) {
+	r.HandleFunc(serviceRoute+"/{service_uri:.*}", s.ServiceHandler)
+	r.HandleFunc(serviceRoute, func(rw http.ResponseWriter, req *http.Request) {
+		target := url.URL{Path: req.URL.Path + "/", RawQuery: req.URL.RawQuery}
+		http.Redirect(rw, req, target.String(), http.StatusMovedPermanently)
+	})
+}
+
+// ServiceHandler proxies the requests to /services/{tunnel_id}/{namespace}/{service}:{port}/{service_uri} to an
+// in-cluster HTTP(S) service of the edge cluster, which must be in the services allowlist of the ClusterConnect.
+func (s *Server) ServiceHandler(rw http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	vars := mux.Vars(req)
+	tunnelID := vars["tunnel_id"]
+
+	// Record the metrics of every response, including the errors of the gateway.
+	labels := newProxyLabels(req, proxyutil.KubernetesURI(vars))
+	w := &proxyutil.ResponseRecorder{ResponseWriter: rw}
+	defer recordMetrics(&labels, w, start)
+	rw = w
+
+	timeout := requestTimeout(req, labels, s.requestTimeout)
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	if s.rejectSuspended(rw, tunnelID) {
+		return
+	}
+	labels.setTunnel(tunnelID)
+
+	port, err := strconv.ParseInt(vars["port"], 10, 32)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("invalid port %s", vars["port"]), http.StatusBadRequest)
+		return
+	}
+	service, err := s.kubeclient.GetService(tunnelID, vars["namespace"], vars["service"], int32(port))
+	if err != nil {
+		log.Errorf("Error getting services of tunnel %s: %s", tunnelID, err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if service == nil {
+		log.Infof("[%s] Rejecting request to service %s/%s:%d not in the allowlist",
+			tunnelID, vars["namespace"], vars["service"], port)
+		http.Error(rw, fmt.Sprintf("service %s/%s:%d is not allowed on cluster %s",
+			vars["namespace"], vars["service"], port, tunnelID), http.StatusForbidden)
+		return
+	}
+
+	client, err := s.getClient(tunnelID)
+	if err != nil {
+		log.Errorf("Error getting client for tunnel %s: %s", tunnelID, err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	target := &url.URL{
+		Scheme: service.Scheme,
+		Host:   fmt.Sprintf("%s.%s.svc:%d", service.Name, service.Namespace, service.Port),
+	}
+	prefix := fmt.Sprintf("/services/%s/%s/%s:%d", tunnelID, service.Namespace, service.Name, service.Port)
+	transport := client.serviceTransport
+	if service.InsecureSkipTLSVerify {
+		transport = client.insecureServiceTransport
+	}
+	log.Debugf("[%s] REQ OK service=%s t=%s %+v", tunnelID, target, timeout, req)
+
+	proxyHandler := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.Out.URL.Path = "/" + vars["service_uri"]
+			r.Out.URL.RawPath = ""
+			r.SetXForwarded()
+			r.Out.Header.Set("X-Forwarded-Prefix", prefix)
+
+			// The credentials of the gateway users must not leak to the services: their JWT, and the cookies of the
+			// origin of the gateway, such as the session cookies of the ingress or of the orchestrator UI.
+			if _, ok := middleware.ClaimsFromContext(r.In.Context()); ok {
+				r.Out.Header.Del("Authorization")
+			}
+			r.Out.Header.Del("Cookie")
+			r.Out.Header.Del("Proxy-Authorization")
+		},
+		// The absolute paths of the redirects and cookies of the service are below the service prefix.
+		ModifyResponse: func(resp *http.Response) error {
+			if location := resp.Header.Get("Location"); location != "" {
+				resp.Header.Set("Location", prefixPath(location, prefix))
+			}
+			cookies := resp.Header.Values("Set-Cookie")
+			for i := range cookies {
+				cookies[i] = prefixCookiePath(cookies[i], prefix)
+			}
+			return nil
+		},
+		Transport: transport,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Debugf("[%s] REQ to service %s failed: %v", tunnelID, target, err)
+			w.WriteHeader(http.StatusBadGateway)
+		},
+	}
+	proxyHandler.ServeHTTP(rw, req)
+}
+
+// prefixPath prefixes a given URL with a given prefix if it is an absolute path, which is not already prefixed.
+// The URLs with a host, and the relative paths, are kept.
+func prefixPath(rawURL, prefix string) string {
+	if !strings.HasPrefix(rawURL, "/") || strings.HasPrefix(rawURL, "//") ||
+		rawURL == prefix || strings.HasPrefix(rawURL, prefix+"/") {
+		return rawURL
+	}
+	return prefix + rawURL
+}
+
+// prefixCookiePath prefixes the Path attribute of a given Set-Cookie header value with a given prefix.
+func prefixCookiePath(cookie, prefix string) string {
+	attributes := strings.Split(cookie, ";")
+	// The first attribute is the name and value of the cookie.
+	for i := 1; i < len(attributes); i++ {
+		name, value, ok := strings.Cut(strings.TrimSpace(attributes[i]), "=")
+		if ok && strings.EqualFold(name, "Path") {
+			attributes[i] = " " + name + "=" + prefixPath(value, prefix)
+		}
+	}
+	return strings.Join(attributes, ";")
+}
+
+// newServiceTransport creates the transport to the in-cluster services of an edge cluster, which dials through the
+// tunnel like the client of its Kubernetes API. The certificates of the https services are verified against the
+// certificate authority of the kubeconfig, unless insecureSkipVerify is set, and the client certificate of the
+// kubeconfig is not presented.
+func newServiceTransport(restCfg *rest.Config, insecureSkipVerify bool) (*http.Transport, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if insecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true // #nosec G402 -- opted in per service in the ClusterConnect
+	} else if len(restCfg.CAData) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(restCfg.CAData) {
+			return nil, errors.New("invalid certificate authority data in kubeconfig")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return &http.Transport{
+		DialContext:     restCfg.Dial,
+		TLSClientConfig: tlsConfig,
+		IdleConnTimeout: serviceIdleConnTimeout,
+	}, nil
+}
@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+
+	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+)
+
+var _ = Describe("Service proxy", func() {
+	var (
+		kc       *fakeKubeclient
+		s        *Server
+		gateway  *httptest.Server
+		service  *httptest.Server
+		received *http.Request
+		client   *Client
+	)
+
+	BeforeEach(func() {
+		received = nil
+		service = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			received = req
+			if req.URL.Path == "/logout" {
+				http.SetCookie(rw, &http.Cookie{Name: "session", Value: "", Path: "/", HttpOnly: true})
+				http.Redirect(rw, req, "/login?redirect=%2F", http.StatusFound)
+				return
+			}
+			_, _ = io.WriteString(rw, "grafana")
+		}))
+
+		kc = &fakeKubeclient{services: []v1alpha1.ClusterService{
+			{Namespace: "monitoring", Name: "grafana", Port: 80, Scheme: "http"},
+			{Namespace: "vault", Name: "vault", Port: 8200, Scheme: "https"},
+			{Namespace: "vault", Name: "vault", Port: 8201, Scheme: "https", InsecureSkipTLSVerify: true},
+		}}
+		var err error
+		s, err = NewServer(WithKubeClient(kc), WithReplicaName("gateway-0"))
+		Expect(err).NotTo(HaveOccurred())
+
+		// The cached client of the tunnel dials the test service instead of the tunnel.
+		client = &Client{serviceTransport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, service.Listener.Addr().String())
+			},
+		}}
+		s.clients.add("test-tunnel", client)
+		gateway = httptest.NewServer(s.router)
+	})
+
+	AfterEach(func() {
+		gateway.Close()
+		service.Close()
+	})
+
+	It("should proxy the requests to an allowed service", func() {
+		resp, err := http.Get(gateway.URL + "/services/test-tunnel/monitoring/grafana:80/api/health?verbose=true")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("grafana"))
+
+		Expect(received.Host).To(Equal("grafana.monitoring.svc:80"))
+		Expect(received.URL.Path).To(Equal("/api/health"))
+		Expect(received.URL.RawQuery).To(Equal("verbose=true"))
+		Expect(received.Header.Get("X-Forwarded-Prefix")).To(Equal("/services/test-tunnel/monitoring/grafana:80"))
+	})
+
+	It("should not forward the credentials of the gateway origin to a service", func() {
+		req, err := http.NewRequest(http.MethodGet, gateway.URL+"/services/test-tunnel/monitoring/grafana:80/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.AddCookie(&http.Cookie{Name: "orch-session", Value: "secret"})
+		req.Header.Set("Proxy-Authorization", "Basic b3JjaDpzZWNyZXQ=")
+		req.Header.Set("Accept", "text/html")
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(received.Header).NotTo(HaveKey("Cookie"))
+		Expect(received.Header).NotTo(HaveKey("Proxy-Authorization"))
+		Expect(received.Header.Get("Accept")).To(Equal("text/html"))
+	})
+
+	It("should prefix the absolute paths of the redirects and cookies of a service", func() {
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		resp, err := client.Get(gateway.URL + "/services/test-tunnel/monitoring/grafana:80/logout")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusFound))
+		Expect(resp.Header.Get("Location")).To(Equal("/services/test-tunnel/monitoring/grafana:80/login?redirect=%2F"))
+		Expect(resp.Header.Get("Set-Cookie")).To(Equal("session=; Path=/services/test-tunnel/monitoring/grafana:80/; HttpOnly"))
+	})
+
+	It("should strip the impersonation headers of the external requests when the impersonation is disabled", func() {
+		external, err := NewServer(WithKubeClient(kc), WithReplicaName("gateway-0"), WithExternalHost("127.0.0.1"))
+		Expect(err).NotTo(HaveOccurred())
+		external.clients.add("test-tunnel", client)
+		externalGateway := httptest.NewServer(external.router)
+		defer externalGateway.Close()
+
+		req, err := http.NewRequest(http.MethodGet, externalGateway.URL+"/services/test-tunnel/monitoring/grafana:80/", nil)
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Impersonate-User", "admin")
+		req.Header.Set("Impersonate-Group", "system:masters")
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(received.Header).NotTo(HaveKey("Impersonate-User"))
+		Expect(received.Header).NotTo(HaveKey("Impersonate-Group"))
+	})
+
+	It("should redirect the requests to a service without a trailing slash", func() {
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		resp, err := client.Get(gateway.URL + "/services/test-tunnel/monitoring/grafana:80?orgId=1")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusMovedPermanently))
+		Expect(resp.Header.Get("Location")).To(Equal("/services/test-tunnel/monitoring/grafana:80/?orgId=1"))
+	})
+
+	It("should reject the requests to a service not in the allowlist", func() {
+		for _, path := range []string{
+			"/services/test-tunnel/monitoring/grafana:3000/",
+			"/services/test-tunnel/monitoring/prometheus:80/",
+			"/services/test-tunnel/default/grafana:80/",
+		} {
+			resp, err := http.Get(gateway.URL + path)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusForbidden), path)
+		}
+		Expect(received).To(BeNil())
+	})
+
+	It("should verify the certificate of an https service unless skipped", func() {
+		vault := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = io.WriteString(rw, "vault")
+		}))
+		defer vault.Close()
+
+		restCfg := &rest.Config{Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, vault.Listener.Addr().String())
+		}}
+		serviceTransport, err := newServiceTransport(restCfg, false)
+		Expect(err).NotTo(HaveOccurred())
+		insecureServiceTransport, err := newServiceTransport(restCfg, true)
+		Expect(err).NotTo(HaveOccurred())
+		s.clients.add("vault-tunnel", &Client{
+			serviceTransport:         serviceTransport,
+			insecureServiceTransport: insecureServiceTransport,
+		})
+
+		// The certificate of the test server is not signed by the certificate authority of the kubeconfig.
+		resp, err := http.Get(gateway.URL + "/services/vault-tunnel/vault/vault:8200/")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusBadGateway))
+
+		resp, err = http.Get(gateway.URL + "/services/vault-tunnel/vault/vault:8201/")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("vault"))
+	})
+
+	It("should reject the requests to a suspended tunnel", func() {
+		kc.setSuspended(true)
+
+		resp, err := http.Get(gateway.URL + "/services/test-tunnel/monitoring/grafana:80/")
+		Expect(err).NotTo(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(received).To(BeNil())
+	})
+})
+
+var _ = Describe("prefixPath", func() {
+	const prefix = "/services/test-tunnel/monitoring/grafana:80"
+
+	It("should prefix the absolute paths only", func() {
+		Expect(prefixPath("/login", prefix)).To(Equal(prefix + "/login"))
+		Expect(prefixPath("/", prefix)).To(Equal(prefix + "/"))
+		Expect(prefixPath(prefix+"/login", prefix)).To(Equal(prefix + "/login"))
+		Expect(prefixPath("login", prefix)).To(Equal("login"))
+		Expect(prefixPath("//grafana.example.com/login", prefix)).To(Equal("//grafana.example.com/login"))
+		Expect(prefixPath("https://grafana.example.com/login", prefix)).To(Equal("https://grafana.example.com/login"))
+	})
+})
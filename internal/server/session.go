@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rancher/remotedialer"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/agent"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/kubeutil"
+)
+
+const (
+	// maxControlFramePayloadSize is the maximum payload size of a websocket control frame.
+	maxControlFramePayloadSize = 125
+	closeFrameWriteTimeout     = time.Second
+)
+
+type agentSessionKey struct{}
+
+// agentSession tracks a single /connect request from the authorization to the end of the tunnel session.
+type agentSession struct {
+	tunnelID string
+	info     kubeutil.SessionInfo
+
+	// connectedRecorded is closed once the session start is recorded,
+	// so that the session end is never recorded before its start.
+	connectedRecorded chan struct{}
+
+	// suspended is set by the authorizer when the agent is rejected because the tunnel is suspended.
+	suspended bool
+
+	mu          sync.Mutex
+	conn        *trackedConn
+	readErr     error
+	closeReason string
+}
+
+func (a *agentSession) setReadErr(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.readErr == nil {
+		a.readErr = err
+	}
+}
+
+// close closes the tunnel connection from the gateway side with a given websocket close code and reason.
+func (a *agentSession) close(code int, reason string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn == nil || a.closeReason != "" {
+		return
+	}
+	a.closeReason = reason
+	if err := a.conn.writeCloseFrame(code, reason); err != nil {
+		log.Debugf("Failed to send close frame for tunnel %s: %v", a.tunnelID, err)
+	}
+	if err := a.conn.Close(); err != nil {
+		log.Warnf("Failed to close session for tunnel %s: %v", a.tunnelID, err)
+	}
+}
+
+// disconnectReason returns a human readable reason for the end of the session.
+func (a *agentSession) disconnectReason() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var netErr net.Error
+	switch {
+	case a.closeReason != "":
+		return a.closeReason
+	case a.readErr == nil:
+		return "session closed"
+	case errors.Is(a.readErr, io.EOF):
+		return "connection closed by agent"
+	case errors.Is(a.readErr, net.ErrClosed):
+		return "connection closed by gateway"
+	case errors.As(a.readErr, &netErr) && netErr.Timeout():
+		return "connection timed out"
+	default:
+		return a.readErr.Error()
+	}
+}
+
+// trackSessionAuthorizer wraps a given authorizer to pass the tunnel ID of an authorized agent to the ConnectHandler.
+func trackSessionAuthorizer(authorizer remotedialer.Authorizer) remotedialer.Authorizer {
+	return func(req *http.Request) (clientKey string, authed bool, err error) {
+		clientKey, authed, err = authorizer(req)
+		if session, ok := req.Context().Value(agentSessionKey{}).(*agentSession); ok && err == nil && authed {
+			session.tunnelID = clientKey
+		}
+		return clientKey, authed, err
+	}
+}
+
+// ConnectHandler serves the tunnel connection requests from agents and records the session details
+// in the ClusterConnect status.
+func (s *Server) ConnectHandler(rw http.ResponseWriter, req *http.Request) {
+	session := &agentSession{
+		info: kubeutil.SessionInfo{
+			RemoteAddress:  remoteAddress(req),
+			AgentVersion:   req.Header.Get(agent.AgentVersionHeader),
+			GatewayReplica: s.replicaName,
+		},
+		connectedRecorded: make(chan struct{}),
+	}
+	req = req.WithContext(context.WithValue(req.Context(), agentSessionKey{}, session))
+
+	w := &sessionResponseWriter{ResponseWriter: rw, server: s, session: session}
+	s.remotedialer.ServeHTTP(w, req)
+
+	if !w.hijacked {
+		return
+	}
+	defer s.activeSessions.Add(-1)
+	s.sessions.Delete(session)
+
+	<-session.connectedRecorded
+	reason := session.disconnectReason()
+	log.Infof("Session for tunnel %s closed: %s", session.tunnelID, reason)
+	if err := s.kubeclient.UpdateSessionDisconnected(session.tunnelID, session.info, reason); err != nil {
+		log.Warnf("Failed to update session status for tunnel %s: %v", session.tunnelID, err)
+	}
+}
+
+// closeSessions closes all the sessions of a given tunnel held by this gateway replica.
+func (s *Server) closeSessions(tunnelID, reason string) {
+	s.sessions.Range(func(key, _ any) bool {
+		if session := key.(*agentSession); session.tunnelID == tunnelID {
+			log.Infof("Closing session for tunnel %s: %s", tunnelID, reason)
+			session.close(websocket.CloseNormalClosure, reason)
+		}
+		return true
+	})
+}
+
+// closeAllSessions closes all the sessions held by this gateway replica with a given websocket close code.
+func (s *Server) closeAllSessions(code int, reason string) {
+	s.sessions.Range(func(key, _ any) bool {
+		session := key.(*agentSession)
+		log.Infof("Closing session for tunnel %s: %s", session.tunnelID, reason)
+		session.close(code, reason)
+		return true
+	})
+}
+
+func (s *Server) recordSessionConnected(session *agentSession) {
+	defer close(session.connectedRecorded)
+	if err := s.kubeclient.UpdateSessionConnected(session.tunnelID, session.info); err != nil {
+		log.Warnf("Failed to update session status for tunnel %s: %v", session.tunnelID, err)
+	}
+}
+
+// remoteAddress returns the address of the agent, honoring the X-Forwarded-For header set by an ingress.
+func remoteAddress(req *http.Request) string {
+	if forwarded := req.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
+
+// sessionResponseWriter detects when the remotedialer upgrades the request to a websocket session
+// and wraps the hijacked connection to learn why the session ended.
+type sessionResponseWriter struct {
+	http.ResponseWriter
+	server   *Server
+	session  *agentSession
+	hijacked bool
+}
+
+func (w *sessionResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The tunnel ID is set by the authorizer. It is empty only for peer connections, which are not tracked.
+	if w.session.tunnelID == "" {
+		return conn, brw, nil
+	}
+
+	w.hijacked = true
+	w.server.activeSessions.Add(1)
+	w.session.info.ConnectedAt = time.Now()
+	go w.server.recordSessionConnected(w.session)
+
+	tracked := &trackedConn{Conn: conn, session: w.session}
+	w.session.mu.Lock()
+	w.session.conn = tracked
+	w.session.mu.Unlock()
+	w.server.sessions.Store(w.session, struct{}{})
+
+	// Keep the data already buffered by the HTTP server in front of the tracked connection.
+	var reader io.Reader = tracked
+	if n := brw.Reader.Buffered(); n > 0 {
+		buffered, err := brw.Reader.Peek(n)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader = io.MultiReader(bytes.NewReader(buffered), tracked)
+	}
+
+	return tracked, bufio.NewReadWriter(bufio.NewReader(reader), bufio.NewWriter(tracked)), nil
+}
+
+func (w *sessionResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// trackedConn records the first read error of the tunnel connection.
+type trackedConn struct {
+	net.Conn
+	session *agentSession
+
+	// writeMu keeps the close frame written by the gateway from interleaving with the writes of the websocket connection.
+	writeMu sync.Mutex
+}
+
+func (c *trackedConn) Write(b []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.Conn.Write(b)
+}
+
+// writeCloseFrame writes a websocket close frame with a given code and reason, so that the agent learns
+// why the gateway ended the session. Frames sent by a server are not masked.
+func (c *trackedConn) writeCloseFrame(code int, reason string) error {
+	payload := websocket.FormatCloseMessage(code, reason)
+	if len(payload) > maxControlFramePayloadSize {
+		payload = payload[:maxControlFramePayloadSize]
+	}
+	frame := append([]byte{0x80 | websocket.CloseMessage, byte(len(payload))}, payload...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.Conn.SetWriteDeadline(time.Now().Add(closeFrameWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := c.Conn.Write(frame)
+	return err
+}
+
+func (c *trackedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil {
+		c.session.setReadErr(err)
+	}
+	return n, err
+}
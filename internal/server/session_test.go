@@ -0,0 +1,290 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/agent"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/kubeutil"
+)
+
+// fakeKubeclient is a Kubeclient implementation that records the session updates.
+type fakeKubeclient struct {
+	mu           sync.Mutex
+	connected    []kubeutil.SessionInfo
+	disconnected []string
+	probes       map[string]bool
+	suspended    bool
+	suspendedErr error
+	services     []v1alpha1.ClusterService
+	handlers     []func(string, bool)
+}
+
+func (f *fakeKubeclient) GetCerts(string) (*x509.CertPool, tls.Certificate, error) {
+	return nil, tls.Certificate{}, nil
+}
+
+func (f *fakeKubeclient) InvalidateCerts(string) error { return nil }
+
+func (f *fakeKubeclient) GetKubeconfig(string) (*api.Config, error) { return api.NewConfig(), nil }
+
+func (f *fakeKubeclient) InvalidateKubeconfig(string) error { return nil }
+
+func (f *fakeKubeclient) UpdateConnectionProbe(tunnelId string, hasSession bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.probes == nil {
+		f.probes = map[string]bool{}
+	}
+	f.probes[tunnelId] = hasSession
+	return nil
+}
+
+func (f *fakeKubeclient) UpdateSessionConnected(_ string, session kubeutil.SessionInfo) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = append(f.connected, session)
+	return nil
+}
+
+func (f *fakeKubeclient) UpdateSessionDisconnected(_ string, _ kubeutil.SessionInfo, reason string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.disconnected = append(f.disconnected, reason)
+	return nil
+}
+
+func (f *fakeKubeclient) IsSuspended(string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.suspended, f.suspendedErr
+}
+
+func (f *fakeKubeclient) GetService(_, namespace, name string, port int32) (*v1alpha1.ClusterService, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, service := range f.services {
+		if service.Namespace == namespace && service.Name == name && service.Port == port {
+			return &service, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeKubeclient) OnTunnelChange(handler func(string, bool)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers = append(f.handlers, handler)
+}
+
+// changeTunnel notifies the handlers of a change, or of the deletion, of the ClusterConnect or kubeconfig of a given
+// tunnel.
+func (f *fakeKubeclient) changeTunnel(tunnelId string, deleted bool) {
+	f.mu.Lock()
+	handlers := append([]func(string, bool){}, f.handlers...)
+	f.mu.Unlock()
+	for _, handler := range handlers {
+		handler(tunnelId, deleted)
+	}
+}
+
+func (f *fakeKubeclient) setSuspended(suspended bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.suspended = suspended
+}
+
+func (f *fakeKubeclient) connectedSessions() []kubeutil.SessionInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]kubeutil.SessionInfo{}, f.connected...)
+}
+
+func (f *fakeKubeclient) connectionProbes() map[string]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	probes := map[string]bool{}
+	for tunnelId, hasSession := range f.probes {
+		probes[tunnelId] = hasSession
+	}
+	return probes
+}
+
+func (f *fakeKubeclient) disconnectReasons() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.disconnected...)
+}
+
+var _ = Describe("ConnectHandler", func() {
+	var (
+		kc      *fakeKubeclient
+		s       *Server
+		gateway *httptest.Server
+	)
+
+	BeforeEach(func() {
+		kc = &fakeKubeclient{}
+		authorizer := func(req *http.Request) (string, bool, error) {
+			return req.Header.Get(agent.TunnelIdHeader), req.Header.Get(agent.TokenHeader) == "valid", nil
+		}
+
+		var err error
+		s, err = NewServer(
+			WithKubeClient(kc),
+			WithAuthorizer(authorizer, false),
+			WithReplicaName("gateway-0"),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		gateway = httptest.NewServer(s.router)
+	})
+
+	AfterEach(func() {
+		gateway.Close()
+	})
+
+	dial := func(token string) (*websocket.Conn, *http.Response, error) {
+		url := "ws" + strings.TrimPrefix(gateway.URL, "http") + "/connect"
+		return websocket.DefaultDialer.Dial(url, http.Header{
+			agent.TunnelIdHeader:     {"test-tunnel"},
+			agent.TokenHeader:        {token},
+			agent.AgentVersionHeader: {"v1.2.3"},
+			"X-Forwarded-For":        {"192.0.2.10, 10.0.0.1"},
+		})
+	}
+
+	It("should record the session start and end", func() {
+		conn, _, err := dial("valid")
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(kc.connectedSessions).Should(HaveLen(1))
+		session := kc.connectedSessions()[0]
+		Expect(session.RemoteAddress).To(Equal("192.0.2.10"))
+		Expect(session.AgentVersion).To(Equal("v1.2.3"))
+		Expect(session.GatewayReplica).To(Equal("gateway-0"))
+		Expect(session.ConnectedAt).NotTo(BeZero())
+
+		Expect(conn.Close()).To(Succeed())
+		Eventually(kc.disconnectReasons).Should(Equal([]string{"connection closed by agent"}))
+	})
+
+	It("should not record a session for an unauthorized agent", func() {
+		_, resp, err := dial("invalid")
+		Expect(err).To(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		Consistently(kc.connectedSessions).Should(BeEmpty())
+		Expect(kc.disconnectReasons()).To(BeEmpty())
+	})
+
+	It("should reject the agent of a suspended tunnel", func() {
+		kc.setSuspended(true)
+
+		_, resp, err := dial("valid")
+		Expect(err).To(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+		Expect(kc.connectedSessions()).To(BeEmpty())
+	})
+
+	It("should close the session once the tunnel is suspended", func() {
+		conn, _, err := dial("valid")
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+		Eventually(kc.connectedSessions).Should(HaveLen(1))
+
+		s.closeSuspendedSessions()
+		Consistently(kc.disconnectReasons).Should(BeEmpty())
+
+		kc.setSuspended(true)
+		s.closeSuspendedSessions()
+		Eventually(kc.disconnectReasons).Should(Equal([]string{suspendedSessionReason}))
+
+		// The access is restored once the tunnel is resumed.
+		kc.setSuspended(false)
+		conn, _, err = dial("valid")
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+		Eventually(kc.connectedSessions).Should(HaveLen(2))
+	})
+
+	It("should probe the connection of idle sessions", func() {
+		conn, _, err := dial("valid")
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+		Eventually(kc.connectedSessions).Should(HaveLen(1))
+
+		s.checkHttpClientsConnection()
+		Expect(kc.connectionProbes()).To(Equal(map[string]bool{"test-tunnel": true}))
+	})
+
+	It("should reject new Kubernetes API requests while shutting down", func() {
+		s.draining.Store(true)
+
+		resp, err := http.Get(gateway.URL + "/kubernetes/test-tunnel/api/v1/namespaces")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+	})
+
+	It("should close the sessions with a going away code once the in-flight requests are drained", func() {
+		conn, _, err := dial("valid")
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+		Eventually(kc.connectedSessions).Should(HaveLen(1))
+
+		// Hold an in-flight request.
+		s.inflightRequests.Add(1)
+		s.httpServers = []*http.Server{gateway.Config}
+		done := make(chan error, 1)
+		go func() {
+			done <- s.shutdown()
+		}()
+
+		Consistently(kc.disconnectReasons, time.Second).Should(BeEmpty())
+		s.inflightRequests.Add(-1)
+
+		_, _, err = conn.ReadMessage()
+		Expect(websocket.IsCloseError(err, websocket.CloseGoingAway)).To(BeTrue(), "unexpected error: %v", err)
+		Eventually(kc.disconnectReasons).Should(Equal([]string{"gateway shutting down"}))
+		Eventually(done, 5*time.Second).Should(Receive(BeNil()))
+	})
+
+	It("should deny the Kubernetes API requests of a suspended tunnel", func() {
+		kc.setSuspended(true)
+
+		resp, err := http.Get(gateway.URL + "/kubernetes/test-tunnel/api/v1/namespaces")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+	})
+
+	It("should answer 404 to the Kubernetes API requests of an unknown tunnel", func() {
+		kc.mu.Lock()
+		kc.suspendedErr = apierrors.NewNotFound(v1alpha1.GroupVersion.WithResource("clusterconnects").GroupResource(), "test-tunnel")
+		kc.mu.Unlock()
+
+		resp, err := http.Get(gateway.URL + "/kubernetes/test-tunnel/api/v1/namespaces")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusNotFound))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(ContainSubstring("cluster test-tunnel not found"))
+	})
+})
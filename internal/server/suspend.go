@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/rancher/remotedialer"
+)
+
+const suspendedSessionReason = "tunnel suspended"
+
+// suspensionAuthorizer wraps a given authorizer to reject the agents of suspended tunnels.
+func (s *Server) suspensionAuthorizer(authorizer remotedialer.Authorizer) remotedialer.Authorizer {
+	return func(req *http.Request) (clientKey string, authed bool, err error) {
+		clientKey, authed, err = authorizer(req)
+		if err != nil || !authed {
+			return clientKey, authed, err
+		}
+
+		suspended, err := s.kubeclient.IsSuspended(clientKey)
+		if err != nil {
+			return clientKey, false, fmt.Errorf("failed to check suspension of tunnel %s: %w", clientKey, err)
+		}
+		if suspended {
+			log.Infof("Rejecting agent for suspended tunnel %s", clientKey)
+			if session, ok := req.Context().Value(agentSessionKey{}).(*agentSession); ok {
+				session.suspended = true
+			}
+			return clientKey, false, nil
+		}
+
+		return clientKey, true, nil
+	}
+}
+
+// suspensionErrorWriter wraps a given error writer to answer with 403 to the agents of suspended tunnels,
+// instead of the 401 written by the remotedialer for any rejected agent.
+func suspensionErrorWriter(errorWriter remotedialer.ErrorWriter) remotedialer.ErrorWriter {
+	return func(rw http.ResponseWriter, req *http.Request, code int, err error) {
+		if session, ok := req.Context().Value(agentSessionKey{}).(*agentSession); ok && session.suspended {
+			errorWriter(rw, req, http.StatusForbidden, fmt.Errorf("tunnel is suspended"))
+			return
+		}
+		errorWriter(rw, req, code, err)
+	}
+}
+
+// closeSuspendedSessions closes the sessions held by this replica for the tunnels that have been suspended since.
+func (s *Server) closeSuspendedSessions() {
+	checked := map[string]bool{}
+	s.sessions.Range(func(key, _ any) bool {
+		tunnelID := key.(*agentSession).tunnelID
+		if checked[tunnelID] {
+			return true
+		}
+		checked[tunnelID] = true
+
+		suspended, err := s.kubeclient.IsSuspended(tunnelID)
+		if err != nil {
+			log.Warnf("Failed to check suspension of tunnel %s: %v", tunnelID, err)
+			return true
+		}
+		if suspended {
+			s.closeSessions(tunnelID, suspendedSessionReason)
+		}
+		return true
+	})
+}
@@ -0,0 +1,129 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/rancher/remotedialer"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+)
+
+// WithTLS serves HTTPS on a given address, in addition to the plain HTTP listener used from within the cluster.
+// The certificate and key files are reloaded whenever they change.
+func WithTLS(addr, certFile, keyFile string) ServerOptions {
+	return func(s *Server) {
+		s.tlsListenAddr = addr
+		s.tlsCertFile = certFile
+		s.tlsKeyFile = keyFile
+	}
+}
+
+// WithAgentClientCA requires the agents to present a client certificate signed by a CA of a given PEM file
+// on /connect. The file is read on each connection, so that the CA can be rotated without a restart.
+func WithAgentClientCA(caFile string) ServerOptions {
+	return func(s *Server) {
+		s.agentClientCAFile = caFile
+	}
+}
+
+// initTLS loads the TLS certificate and the agent client CA, so that a misconfiguration fails the startup.
+func (s *Server) initTLS() (err error) {
+	if s.tlsCertFile != "" || s.tlsKeyFile != "" {
+		if s.tlsListenAddr == "" {
+			return errors.New("TLS listen address is not set")
+		}
+		s.certWatcher, err = certwatcher.New(s.tlsCertFile, s.tlsKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load the TLS certificate: %w", err)
+		}
+	}
+
+	if s.agentClientCAFile != "" {
+		if s.certWatcher == nil {
+			return errors.New("agent client CA requires TLS")
+		}
+		if _, err := loadCertPool(s.agentClientCAFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) newTLSServer() *http.Server {
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: s.certWatcher.GetCertificate,
+	}
+	if s.agentClientCAFile != "" {
+		// The client certificate is requested without being verified in the handshake, so that the Kubernetes API
+		// clients presenting a certificate of the downstream cluster are not rejected. It is verified on /connect only.
+		tlsConfig.ClientAuth = tls.RequestClientCert
+	}
+
+	return &http.Server{
+		Addr:      s.tlsListenAddr,
+		Handler:   s.tlsRouter,
+		TLSConfig: tlsConfig,
+		// Disable HTTP/2, as the agent sessions and the kubectl streams are HTTP/1.1 upgrades.
+		TLSNextProto: map[string]func(*http.Server, *tls.Conn, http.Handler){},
+	}
+}
+
+// agentClientCertAuthorizer wraps a given authorizer to reject the agents that don't present a client certificate
+// signed by the agent client CA.
+func (s *Server) agentClientCertAuthorizer(authorizer remotedialer.Authorizer) remotedialer.Authorizer {
+	if s.agentClientCAFile == "" {
+		return authorizer
+	}
+	return func(req *http.Request) (clientKey string, authed bool, err error) {
+		if err := s.verifyAgentClientCert(req); err != nil {
+			log.Infof("Rejecting agent from %s: %v", remoteAddress(req), err)
+			return "", false, nil
+		}
+		return authorizer(req)
+	}
+}
+
+func (s *Server) verifyAgentClientCert(req *http.Request) error {
+	if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+		return errors.New("no client certificate")
+	}
+
+	roots, err := loadCertPool(s.agentClientCAFile)
+	if err != nil {
+		return err
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range req.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = req.TLS.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("invalid client certificate: %w", err)
+	}
+	return nil
+}
+
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the agent client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate found in the agent client CA %s", caFile)
+	}
+	return pool, nil
+}
@@ -0,0 +1,192 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/agent"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/certutil"
+)
+
+// newTestCertificate issues a client certificate signed by a given CA, or a self-signed CA if parent is nil.
+func newTestCertificate(parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, tls.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "test-tunnel"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+	return cert, key, tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+var _ = Describe("TLS", func() {
+	var (
+		dir               string
+		certFile, keyFile string
+		caFile            string
+		ca                *x509.Certificate
+		caKey             *ecdsa.PrivateKey
+		authorizer        = func(req *http.Request) (string, bool, error) { return req.Header.Get(agent.TunnelIdHeader), true, nil }
+		writeFile         = func(name string, data []byte) string {
+			path := filepath.Join(dir, name)
+			Expect(os.WriteFile(path, data, 0o600)).To(Succeed())
+			return path
+		}
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+
+		cert, key, err := certutil.GenerateTestCertificate()
+		Expect(err).NotTo(HaveOccurred())
+		certFile = writeFile("tls.crt", cert)
+		keyFile = writeFile("tls.key", key)
+
+		ca, caKey, _ = newTestCertificate(nil, nil)
+		caFile = writeFile("ca.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+	})
+
+	It("should fail on an invalid configuration", func() {
+		_, err := NewServer(WithKubeClient(&fakeKubeclient{}), WithReplicaName("gateway-0"),
+			WithTLS("127.0.0.1:8443", certFile, filepath.Join(dir, "missing.key")))
+		Expect(err).To(HaveOccurred())
+
+		_, err = NewServer(WithKubeClient(&fakeKubeclient{}), WithReplicaName("gateway-0"),
+			WithAgentClientCA(caFile))
+		Expect(err).To(MatchError(ContainSubstring("requires TLS")))
+
+		_, err = NewServer(WithKubeClient(&fakeKubeclient{}), WithReplicaName("gateway-0"),
+			WithTLS("127.0.0.1:8443", certFile, keyFile), WithAgentClientCA(certFile+".missing"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should require an agent client certificate signed by the agent client CA", func() {
+		kc := &fakeKubeclient{}
+		s, err := NewServer(
+			WithKubeClient(kc),
+			WithAuthorizer(authorizer, false),
+			WithReplicaName("gateway-0"),
+			WithTLS("127.0.0.1:8443", certFile, keyFile),
+			WithAgentClientCA(caFile),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		gateway := httptest.NewUnstartedServer(s.tlsRouter)
+		gateway.TLS = s.newTLSServer().TLSConfig
+		gateway.StartTLS()
+		defer gateway.Close()
+
+		dial := func(certs ...tls.Certificate) (*websocket.Conn, *http.Response, error) {
+			dialer := &websocket.Dialer{TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true, // #nosec G402 -- self-signed test certificate
+				Certificates:       certs,
+			}}
+			url := "wss" + strings.TrimPrefix(gateway.URL, "https") + "/connect"
+			return dialer.Dial(url, http.Header{agent.TunnelIdHeader: {"test-tunnel"}})
+		}
+
+		By("rejecting an agent without client certificate")
+		_, resp, err := dial()
+		Expect(err).To(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		By("rejecting an agent with a client certificate of another CA")
+		otherCA, otherCAKey, _ := newTestCertificate(nil, nil)
+		_, _, otherCert := newTestCertificate(otherCA, otherCAKey)
+		_, resp, err = dial(otherCert)
+		Expect(err).To(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+
+		By("accepting an agent with a client certificate of the agent client CA")
+		_, _, clientCert := newTestCertificate(ca, caKey)
+		conn, _, err := dial(clientCert)
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+		Eventually(kc.connectedSessions).Should(HaveLen(1))
+
+		By("not requiring the client certificate on the other endpoints")
+		resp, err = gateway.Client().Get(gateway.URL + "/healthz")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should serve only the agent connections and the authenticated external endpoints", func() {
+		s, err := NewServer(
+			WithKubeClient(&fakeKubeclient{}),
+			WithAuthorizer(authorizer, true),
+			WithAuth(true, "localhost", 8181),
+			WithExternalHost("connect-gateway.example.com"),
+			WithAdminToken("admin-token"),
+			WithReplicaName("gateway-0"),
+			WithTLS("127.0.0.1:8443", certFile, keyFile),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		gateway := httptest.NewUnstartedServer(s.tlsRouter)
+		gateway.TLS = s.newTLSServer().TLSConfig
+		gateway.StartTLS()
+		defer gateway.Close()
+
+		get := func(path, host string, header http.Header) int {
+			req, err := http.NewRequest(http.MethodGet, gateway.URL+path, nil)
+			Expect(err).NotTo(HaveOccurred())
+			req.Host = host
+			for name, values := range header {
+				req.Header[name] = values
+			}
+			resp, err := gateway.Client().Do(req)
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			return resp.StatusCode
+		}
+
+		By("authenticating the external endpoints whatever the Host")
+		for _, host := range []string{"connect-gateway.example.com", "cluster-connect-gateway.default.svc"} {
+			Expect(get("/kubernetes/test-tunnel/api/v1/namespaces", host, nil)).To(Equal(http.StatusUnauthorized))
+			Expect(get("/services/test-tunnel/default/web:80/", host, nil)).To(Equal(http.StatusUnauthorized))
+		}
+
+		By("not serving the admin and metrics endpoints")
+		adminAuth := http.Header{"Authorization": {"Bearer admin-token"}}
+		Expect(get("/admin/v1/tunnels", "cluster-connect-gateway.default.svc", adminAuth)).To(Equal(http.StatusNotFound))
+		Expect(get("/metrics", "cluster-connect-gateway.default.svc", nil)).To(Equal(http.StatusNotFound))
+
+		By("serving the health check")
+		Expect(get("/healthz", "cluster-connect-gateway.default.svc", nil)).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeutil
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+)
+
+const (
+	// Labels of the ClusterConnect with the name and namespace of the CAPI kubeconfig Secret.
+	kubeconfigNameLabel      = "cluster.x-k8s.io/kubeconfig-name"
+	kubeconfigNamespaceLabel = "cluster.x-k8s.io/kubeconfig-namespace"
+
+	// clusterNameLabel is set by CAPI on the kubeconfig Secrets, and by the controller on the kubeconfig Secrets of
+	// the clusters not managed by CAPI. Only those Secrets are cached.
+	clusterNameLabel = "cluster.x-k8s.io/cluster-name"
+)
+
+// kubeconfigSecretSelector selects the kubeconfig Secrets.
+func kubeconfigSecretSelector() (labels.Selector, error) {
+	requirement, err := labels.NewRequirement(clusterNameLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
+
+// startInformers starts the informers of the ClusterConnects and of the CAPI kubeconfig Secrets, running until a
+// given context is done, and waits for their initial sync. The reads of the ClusterConnects and kubeconfigs are
+// then served from the informer cache, and the cached kubeconfigs and certificates are invalidated on changes.
+func (k *kubeclient) startInformers(ctx context.Context, config *rest.Config) error {
+	selector, err := kubeconfigSecretSelector()
+	if err != nil {
+		return err
+	}
+	c, err := cache.New(config, cache.Options{
+		Scheme: scheme,
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {Label: selector},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	ccInformer, err := c.GetInformer(ctx, &v1alpha1.ClusterConnect{})
+	if err != nil {
+		return err
+	}
+	if _, err := ccInformer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj any) {
+			oldCC, ok1 := oldObj.(*v1alpha1.ClusterConnect)
+			newCC, ok2 := newObj.(*v1alpha1.ClusterConnect)
+			if ok1 && ok2 && clusterConnectChanged(oldCC, newCC) {
+				k.invalidateTunnel(newCC.Name, false)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if cc, ok := deletedObject(obj).(*v1alpha1.ClusterConnect); ok {
+				k.invalidateTunnel(cc.Name, true)
+			}
+		},
+	}); err != nil {
+		return err
+	}
+
+	secretInformer, err := c.GetInformer(ctx, &corev1.Secret{})
+	if err != nil {
+		return err
+	}
+	if _, err := secretInformer.AddEventHandler(k.secretEventHandler(ctx)); err != nil {
+		return err
+	}
+
+	go func() {
+		if err := c.Start(ctx); err != nil {
+			log.Errorf("Failed to run informer cache: %v", err)
+		}
+	}()
+	if !c.WaitForCacheSync(ctx) {
+		return errors.New("failed to sync informer cache")
+	}
+
+	k.reader = c
+	return nil
+}
+
+// OnTunnelChange registers a handler called with the tunnel ID once the ClusterConnect or the kubeconfig Secret of
+// a tunnel changes. deleted is true once the ClusterConnect is deleted.
+func (k *kubeclient) OnTunnelChange(handler func(tunnelId string, deleted bool)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.handlers = append(k.handlers, handler)
+}
+
+// invalidateTunnel invalidates the cached kubeconfig and certificates of a given tunnel, and notifies the handlers.
+func (k *kubeclient) invalidateTunnel(tunnelId string, deleted bool) {
+	log.Debugf("ClusterConnect or kubeconfig of tunnel %s changed", tunnelId)
+	_ = k.InvalidateKubeconfig(tunnelId)
+	_ = k.InvalidateCerts(tunnelId)
+
+	k.mu.Lock()
+	handlers := append([]func(string, bool){}, k.handlers...)
+	k.mu.Unlock()
+	for _, handler := range handlers {
+		handler(tunnelId, deleted)
+	}
+}
+
+// secretEventHandler invalidates the tunnels of the kubeconfig Secrets whose data changes, or which are deleted.
+func (k *kubeclient) secretEventHandler(ctx context.Context) toolscache.ResourceEventHandlerFuncs {
+	return toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj any) {
+			oldSecret, ok1 := oldObj.(*corev1.Secret)
+			newSecret, ok2 := newObj.(*corev1.Secret)
+			if ok1 && ok2 && !reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+				k.invalidateKubeconfigSecret(ctx, newSecret)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if secret, ok := deletedObject(obj).(*corev1.Secret); ok {
+				k.invalidateKubeconfigSecret(ctx, secret)
+			}
+		},
+	}
+}
+
+// invalidateKubeconfigSecret invalidates the tunnels whose ClusterConnect refers to a given kubeconfig Secret.
+func (k *kubeclient) invalidateKubeconfigSecret(ctx context.Context, secret *corev1.Secret) {
+	ccs := &v1alpha1.ClusterConnectList{}
+	if err := k.cachedReader().List(ctx, ccs, client.MatchingLabels{
+		kubeconfigNameLabel:      secret.Name,
+		kubeconfigNamespaceLabel: secret.Namespace,
+	}); err != nil {
+		log.Errorf("Failed to list ClusterConnects of kubeconfig %s/%s: %v", secret.Namespace, secret.Name, err)
+		return
+	}
+	for i := range ccs.Items {
+		k.invalidateTunnel(ccs.Items[i].Name, false)
+	}
+}
+
+// clusterConnectChanged reports whether a ClusterConnect update changes how its tunnel is accessed. The status
+// updates, e.g. of the connection probe, don't.
+func clusterConnectChanged(oldCC, newCC *v1alpha1.ClusterConnect) bool {
+	return oldCC.Generation != newCC.Generation ||
+		!maps.Equal(oldCC.Labels, newCC.Labels) ||
+		oldCC.DeletionTimestamp.IsZero() != newCC.DeletionTimestamp.IsZero()
+}
+
+func deletedObject(obj any) any {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		return tombstone.Obj
+	}
+	return obj
+}
@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+)
+
+func TestInvalidateKubeconfigSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+
+	newClusterConnect := func(name, kubeconfig string) *v1alpha1.ClusterConnect {
+		return &v1alpha1.ClusterConnect{ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				kubeconfigNameLabel:      kubeconfig,
+				kubeconfigNamespaceLabel: "default",
+			},
+		}}
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newClusterConnect("tunnel-a", "cluster-a-kubeconfig"),
+		newClusterConnect("tunnel-b", "cluster-b-kubeconfig"),
+	).Build()
+
+	kc := &kubeclient{client: fakeClient}
+	kc.kcStore.Store("tunnel-a", api.NewConfig())
+	kc.kcStore.Store("tunnel-b", api.NewConfig())
+	var changed []string
+	kc.OnTunnelChange(func(tunnelId string, _ bool) { changed = append(changed, tunnelId) })
+
+	kc.invalidateKubeconfigSecret(context.Background(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      "cluster-a-kubeconfig",
+		Namespace: "default",
+	}})
+	assert.Equal(t, []string{"tunnel-a"}, changed)
+	_, ok := kc.kcStore.Load("tunnel-a")
+	assert.False(t, ok)
+	_, ok = kc.kcStore.Load("tunnel-b")
+	assert.True(t, ok)
+}
+
+func TestManagedKubeconfigSecretChanged(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+
+	// The kubeconfig Secret of a cluster not managed by CAPI, as labeled by the controller.
+	cc := &v1alpha1.ClusterConnect{ObjectMeta: metav1.ObjectMeta{
+		Name: "tunnel-c",
+		Labels: map[string]string{
+			kubeconfigNameLabel:      "tunnel-c-kubeconfig",
+			kubeconfigNamespaceLabel: "orch-cluster",
+		},
+	}}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tunnel-c-kubeconfig",
+			Namespace: "orch-cluster",
+			Labels:    map[string]string{clusterNameLabel: "tunnel-c"},
+		},
+		Data: map[string][]byte{KubeconfigDataName: []byte("old")},
+	}
+	selector, err := kubeconfigSecretSelector()
+	assert.NoError(t, err)
+	assert.True(t, selector.Matches(labels.Set(secret.Labels)))
+
+	kc := &kubeclient{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cc).Build()}
+	var changed []string
+	kc.OnTunnelChange(func(tunnelId string, _ bool) { changed = append(changed, tunnelId) })
+	handler := kc.secretEventHandler(context.Background())
+
+	// The updates that don't change the kubeconfig are ignored.
+	annotated := secret.DeepCopy()
+	annotated.Annotations = map[string]string{"note": "annotated"}
+	handler.OnUpdate(secret, annotated)
+	assert.Empty(t, changed)
+
+	updated := secret.DeepCopy()
+	updated.Data[KubeconfigDataName] = []byte("new")
+	handler.OnUpdate(secret, updated)
+	assert.Equal(t, []string{"tunnel-c"}, changed)
+}
+
+func TestClusterConnectChanged(t *testing.T) {
+	cc := &v1alpha1.ClusterConnect{ObjectMeta: metav1.ObjectMeta{
+		Name:       "tunnel-a",
+		Generation: 1,
+		Labels:     map[string]string{kubeconfigNameLabel: "cluster-a-kubeconfig"},
+	}}
+
+	// The status updates don't change the access to the tunnel.
+	probed := cc.DeepCopy()
+	probed.Status.ConnectionProbe.LastProbeTimestamp = metav1.Now()
+	assert.False(t, clusterConnectChanged(cc, probed))
+
+	suspended := cc.DeepCopy()
+	suspended.Spec.Suspended = true
+	suspended.Generation++
+	assert.True(t, clusterConnectChanged(cc, suspended))
+
+	relabeled := cc.DeepCopy()
+	relabeled.Labels[kubeconfigNameLabel] = "cluster-a-kubeconfig-2"
+	assert.True(t, clusterConnectChanged(cc, relabeled))
+
+	deleted := cc.DeepCopy()
+	now := metav1.Now()
+	deleted.DeletionTimestamp = &now
+	assert.True(t, clusterConnectChanged(cc, deleted))
+}
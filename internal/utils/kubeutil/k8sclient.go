@@ -9,10 +9,12 @@ import (
 	"crypto/x509"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/atomix/dazl"
 	_ "github.com/atomix/dazl/zap"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -20,6 +22,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -40,9 +43,24 @@ type Kubeclient interface {
 	GetKubeconfig(tunnelId string) (*api.Config, error)
 	InvalidateKubeconfig(tunnelId string) error
 	UpdateConnectionProbe(tunnelId string, hasSession bool) error
+	UpdateSessionConnected(tunnelId string, session SessionInfo) error
+	UpdateSessionDisconnected(tunnelId string, session SessionInfo, reason string) error
+	IsSuspended(tunnelId string) (bool, error)
+	GetService(tunnelId, namespace, name string, port int32) (*v1alpha1.ClusterService, error)
+	OnTunnelChange(handler func(tunnelId string, deleted bool))
 }
 
-func NewInClusterClient() (Kubeclient, error) {
+// SessionInfo describes a connect-agent session established with a gateway replica.
+type SessionInfo struct {
+	RemoteAddress  string
+	AgentVersion   string
+	GatewayReplica string
+	ConnectedAt    time.Time
+}
+
+// NewInClusterClient creates a Kubeclient whose ClusterConnect and kubeconfig reads are served by informers
+// running until a given context is done.
+func NewInClusterClient(ctx context.Context) (Kubeclient, error) {
 	// Initialize the scheme with default Kubernetes and clusterconnects types
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(v1alpha1.AddToScheme(scheme))
@@ -53,7 +71,11 @@ func NewInClusterClient() (Kubeclient, error) {
 		return nil, fmt.Errorf("failed to create client: %v", err)
 	}
 
-	return &kubeclient{certStore: sync.Map{}, kcStore: sync.Map{}, client: client}, nil
+	k := &kubeclient{certStore: sync.Map{}, kcStore: sync.Map{}, client: client}
+	if err := k.startInformers(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to start informers: %v", err)
+	}
+	return k, nil
 }
 
 // kubeclient is a struct that implements the Certkubeclient interface
@@ -61,6 +83,20 @@ type kubeclient struct {
 	certStore sync.Map
 	kcStore   sync.Map
 	client    client.Client
+	// reader serves the ClusterConnects and the kubeconfig Secrets from the informer cache, if started.
+	reader client.Reader
+
+	mu       sync.Mutex
+	handlers []func(tunnelId string, deleted bool)
+}
+
+// cachedReader returns the reader of the ClusterConnects and kubeconfig Secrets, which doesn't hit the API server
+// once the informers are started. The updates must read from the client instead, as the cache may be stale.
+func (k *kubeclient) cachedReader() client.Reader {
+	if k.reader != nil {
+		return k.reader
+	}
+	return k.client
 }
 
 type Certs struct {
@@ -148,19 +184,24 @@ func (k *kubeclient) GetKubeconfig(tunnelId string) (*api.Config, error) {
 		return cached.(*api.Config), nil
 	}
 
-	// Get the cluster connect object from the API server
-	cc, err := k.getClusterConnect(tunnelId)
+	// Get the cluster connect object from the informer cache
+	cc, err := k.getCachedClusterConnect(tunnelId)
 	if err != nil {
 		log.Errorf("Failed to get cluster connect for tunnel %s: %v", tunnelId, err)
 		return nil, err
 	}
 
-	// Get the kubeconfig from the secret reference
+	// Get the kubeconfig from the secret reference. Only the Secrets labeled by CAPI are cached, so the others
+	// are read from the API server.
 	kubeconfig := &corev1.Secret{}
-	err = k.client.Get(context.Background(), types.NamespacedName{
-		Name:      cc.GetLabels()["cluster.x-k8s.io/kubeconfig-name"],
-		Namespace: cc.GetLabels()["cluster.x-k8s.io/kubeconfig-namespace"],
-	}, kubeconfig)
+	key := types.NamespacedName{
+		Name:      cc.GetLabels()[kubeconfigNameLabel],
+		Namespace: cc.GetLabels()[kubeconfigNamespaceLabel],
+	}
+	err = k.cachedReader().Get(context.Background(), key, kubeconfig)
+	if apierrors.IsNotFound(err) && k.reader != nil {
+		err = k.client.Get(context.Background(), key, kubeconfig)
+	}
 	if err != nil {
 		log.Errorf("Failed to get kubeconfig for tunnel %s: %v", tunnelId, err)
 		return nil, err
@@ -188,6 +229,44 @@ func (k *kubeclient) InvalidateKubeconfig(tunnelId string) error {
 	return nil
 }
 
+// IsSuspended reports whether the access through the gateway is suspended for a given tunnel ID.
+// The access is suspended as well once the ClusterConnect is being deleted.
+func (m *kubeclient) IsSuspended(tunnelId string) (bool, error) {
+	cc, err := m.getCachedClusterConnect(tunnelId)
+	if err != nil {
+		return false, err
+	}
+	return cc.Spec.Suspended || !cc.DeletionTimestamp.IsZero(), nil
+}
+
+// GetService returns the service with a given namespace, name and port in the services allowlist of the
+// ClusterConnect of a given tunnel ID, or nil if it is not allowed.
+func (m *kubeclient) GetService(tunnelId, namespace, name string, port int32) (*v1alpha1.ClusterService, error) {
+	cc, err := m.getCachedClusterConnect(tunnelId)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range cc.Spec.Services {
+		if service.Namespace == namespace && service.Name == name && service.Port == port {
+			if service.Scheme == "" {
+				service.Scheme = "http"
+			}
+			return &service, nil
+		}
+	}
+	return nil, nil
+}
+
+// getCachedClusterConnect gets the ClusterConnect of a given tunnel ID from the informer cache, if started.
+func (m *kubeclient) getCachedClusterConnect(tunnelId string) (*v1alpha1.ClusterConnect, error) {
+	cc := &v1alpha1.ClusterConnect{}
+	err := m.cachedReader().Get(context.Background(), types.NamespacedName{Name: tunnelId}, cc)
+	if err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
 func (m *kubeclient) getClusterConnect(tunnelId string) (*v1alpha1.ClusterConnect, error) {
 	cc := &v1alpha1.ClusterConnect{}
 	err := m.client.Get(context.Background(), types.NamespacedName{Name: tunnelId}, cc)
@@ -253,3 +332,85 @@ func (m *kubeclient) UpdateConnectionProbe(tunnelId string, hasSession bool) err
 
 	return nil
 }
+
+// UpdateSessionConnected records a newly established agent session in the ClusterConnect status.
+func (m *kubeclient) UpdateSessionConnected(tunnelId string, session SessionInfo) error {
+	err := m.patchSessionStatus(tunnelId, func(cc *v1alpha1.ClusterConnect) bool {
+		reconnectCount := int32(0)
+		if prev := cc.Status.Session; prev != nil {
+			reconnectCount = prev.ReconnectCount
+			if prev.ConnectedAt != nil {
+				reconnectCount++
+			}
+		}
+
+		var lastDisconnectReason string
+		if cc.Status.Session != nil {
+			lastDisconnectReason = cc.Status.Session.LastDisconnectReason
+		}
+
+		connectedAt := metav1.NewTime(session.ConnectedAt)
+		cc.Status.Session = &v1alpha1.AgentSession{
+			Connected:            true,
+			RemoteAddress:        session.RemoteAddress,
+			ConnectedAt:          &connectedAt,
+			GatewayReplica:       session.GatewayReplica,
+			ReconnectCount:       reconnectCount,
+			AgentVersion:         session.AgentVersion,
+			LastDisconnectReason: lastDisconnectReason,
+		}
+		return true
+	})
+	if err != nil {
+		log.Errorf("Failed to record session for tunnel %s: %v", tunnelId, err)
+		return err
+	}
+
+	log.Debugf("Recorded session for tunnel %s on %s", tunnelId, session.GatewayReplica)
+	return nil
+}
+
+// UpdateSessionDisconnected records the end of an agent session in the ClusterConnect status.
+// The status is left untouched if it already describes a newer session, e.g. one held by another gateway replica.
+func (m *kubeclient) UpdateSessionDisconnected(tunnelId string, session SessionInfo, reason string) error {
+	// Timestamps are stored with second precision.
+	connectedAt := metav1.NewTime(session.ConnectedAt).Rfc3339Copy()
+
+	err := m.patchSessionStatus(tunnelId, func(cc *v1alpha1.ClusterConnect) bool {
+		current := cc.Status.Session
+		if current == nil || current.GatewayReplica != session.GatewayReplica || !current.ConnectedAt.Equal(&connectedAt) {
+			return false
+		}
+
+		now := metav1.Now()
+		current.Connected = false
+		current.DisconnectedAt = &now
+		current.LastDisconnectReason = reason
+		return true
+	})
+	if err != nil {
+		log.Errorf("Failed to record session end for tunnel %s: %v", tunnelId, err)
+		return err
+	}
+
+	log.Debugf("Recorded session end for tunnel %s: %s", tunnelId, reason)
+	return nil
+}
+
+// patchSessionStatus applies a given mutation to the ClusterConnect status and patches it with optimistic locking.
+// The mutation returns false if no update is needed.
+func (m *kubeclient) patchSessionStatus(tunnelId string, mutate func(cc *v1alpha1.ClusterConnect) bool) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cc, err := m.getClusterConnect(tunnelId)
+		if err != nil {
+			return err
+		}
+
+		beforeObj := cc.DeepCopy()
+		if !mutate(cc) {
+			return nil
+		}
+
+		return m.client.Status().Patch(context.Background(), cc, client.MergeFromWithOptions(beforeObj, client.MergeFromWithOptimisticLock{}))
+	})
+}
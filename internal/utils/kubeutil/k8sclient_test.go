@@ -6,11 +6,13 @@ package kubeutil
 import (
 	"sync"
 	"testing"
+	"time"
 
 	v1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
 	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/certutil"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -106,3 +108,158 @@ func TestInvalidateCerts(t *testing.T) {
 	_, ok := kc.certStore.Load("test-tunnel")
 	assert.False(t, ok)
 }
+
+func TestUpdateSession(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+
+	cc := &v1alpha1.ClusterConnect{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-tunnel",
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cc).WithStatusSubresource(cc).Build()
+
+	kc := &kubeclient{
+		certStore: sync.Map{},
+		kcStore:   sync.Map{},
+		client:    fakeClient,
+	}
+
+	getSession := func() *v1alpha1.AgentSession {
+		current, err := kc.getClusterConnect("test-tunnel")
+		assert.NoError(t, err)
+		return current.Status.Session
+	}
+
+	first := SessionInfo{
+		RemoteAddress:  "192.0.2.10",
+		AgentVersion:   "v1.0.0",
+		GatewayReplica: "gateway-0",
+		ConnectedAt:    time.Now().Add(-time.Minute),
+	}
+	assert.NoError(t, kc.UpdateSessionConnected("test-tunnel", first))
+
+	session := getSession()
+	assert.True(t, session.Connected)
+	assert.Equal(t, "192.0.2.10", session.RemoteAddress)
+	assert.Equal(t, "v1.0.0", session.AgentVersion)
+	assert.Equal(t, "gateway-0", session.GatewayReplica)
+	assert.Equal(t, int32(0), session.ReconnectCount)
+
+	// The agent reconnects to another replica before the first session is recorded as closed.
+	second := SessionInfo{
+		RemoteAddress:  "192.0.2.11",
+		AgentVersion:   "v1.0.0",
+		GatewayReplica: "gateway-1",
+		ConnectedAt:    time.Now(),
+	}
+	assert.NoError(t, kc.UpdateSessionConnected("test-tunnel", second))
+
+	// The end of the stale session must not overwrite the live one.
+	assert.NoError(t, kc.UpdateSessionDisconnected("test-tunnel", first, "connection closed by agent"))
+
+	session = getSession()
+	assert.True(t, session.Connected)
+	assert.Equal(t, "gateway-1", session.GatewayReplica)
+	assert.Equal(t, int32(1), session.ReconnectCount)
+	assert.Nil(t, session.DisconnectedAt)
+
+	assert.NoError(t, kc.UpdateSessionDisconnected("test-tunnel", second, "connection timed out"))
+
+	session = getSession()
+	assert.False(t, session.Connected)
+	assert.NotNil(t, session.DisconnectedAt)
+	assert.Equal(t, "connection timed out", session.LastDisconnectReason)
+
+	// The reason of the last disconnect is kept when the agent reconnects.
+	third := second
+	third.ConnectedAt = time.Now().Add(time.Minute)
+	assert.NoError(t, kc.UpdateSessionConnected("test-tunnel", third))
+
+	session = getSession()
+	assert.True(t, session.Connected)
+	assert.Equal(t, int32(2), session.ReconnectCount)
+	assert.Equal(t, "connection timed out", session.LastDisconnectReason)
+}
+
+func TestIsSuspended(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+
+	cc := &v1alpha1.ClusterConnect{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-tunnel",
+		},
+		Spec: v1alpha1.ClusterConnectSpec{
+			Suspended: true,
+		},
+	}
+	now := metav1.Now()
+	deleting := &v1alpha1.ClusterConnect{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name:              "deleting-tunnel",
+			DeletionTimestamp: &now,
+			Finalizers:        []string{"test"},
+		},
+	}
+
+	kc := &kubeclient{
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cc, deleting).Build(),
+	}
+
+	suspended, err := kc.IsSuspended("test-tunnel")
+	assert.NoError(t, err)
+	assert.True(t, suspended)
+
+	suspended, err = kc.IsSuspended("deleting-tunnel")
+	assert.NoError(t, err)
+	assert.True(t, suspended)
+
+	_, err = kc.IsSuspended("unknown-tunnel")
+	assert.Error(t, err)
+}
+
+func TestGetService(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+
+	cc := &v1alpha1.ClusterConnect{
+		ObjectMeta: ctrl.ObjectMeta{
+			Name: "test-tunnel",
+		},
+		Spec: v1alpha1.ClusterConnectSpec{
+			Services: []v1alpha1.ClusterService{
+				{Namespace: "monitoring", Name: "grafana", Port: 80},
+				{Namespace: "monitoring", Name: "prometheus", Port: 9090, Scheme: "https"},
+			},
+		},
+	}
+
+	kc := &kubeclient{
+		client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cc).Build(),
+	}
+
+	service, err := kc.GetService("test-tunnel", "monitoring", "grafana", 80)
+	assert.NoError(t, err)
+	assert.Equal(t, &v1alpha1.ClusterService{Namespace: "monitoring", Name: "grafana", Port: 80, Scheme: "http"}, service)
+
+	service, err = kc.GetService("test-tunnel", "monitoring", "prometheus", 9090)
+	assert.NoError(t, err)
+	assert.Equal(t, "https", service.Scheme)
+
+	service, err = kc.GetService("test-tunnel", "monitoring", "grafana", 3000)
+	assert.NoError(t, err)
+	assert.Nil(t, service)
+
+	service, err = kc.GetService("test-tunnel", "default", "grafana", 80)
+	assert.NoError(t, err)
+	assert.Nil(t, service)
+
+	_, err = kc.GetService("unknown-tunnel", "monitoring", "grafana", 80)
+	assert.Error(t, err)
+}
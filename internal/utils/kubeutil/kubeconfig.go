@@ -7,7 +7,10 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"fmt"
+	"maps"
 	"os"
+	"slices"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -51,6 +54,12 @@ func GenerateKubeconfig(ctx context.Context, c client.Client, clusterName, clust
 		return nil, err
 	}
 
+	return GenerateKubeconfigFromSecrets(clusterName, serverCA, clientClusterCA, server)
+}
+
+// GenerateKubeconfigFromSecrets generates a kubeconfig with a client certificate signed by the client CA.
+// The Secrets are intended to match the format of the <cluster-name>-ca and <cluster-name>-cca secrets used in CAPI.
+func GenerateKubeconfigFromSecrets(clusterName string, serverCA, clientClusterCA *corev1.Secret, server string) ([]byte, error) {
 	clientCACert, err := certs.DecodeCertPEM(clientClusterCA.Data[TLSCrtDataName])
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to decode CA Cert")
@@ -85,6 +94,69 @@ func GenerateKubeconfig(ctx context.Context, c client.Client, clusterName, clust
 	return out, nil
 }
 
+// KubeconfigDrift reports how a given kubeconfig drifted from a given server URL and the APIServer CA in the
+// <cluster-name>-ca Secret of a given cluster. It returns an empty string if the kubeconfig matches both.
+func KubeconfigDrift(ctx context.Context, c client.Client, clusterName, clusterNamespace string, data []byte, server string) (string, error) {
+	serverCA, err := getCertSecret(ctx, c, clusterName, clusterNamespace, ClusterCA)
+	if err != nil {
+		return "", err
+	}
+
+	return kubeconfigDrift(data, serverCA, server)
+}
+
+func kubeconfigDrift(data []byte, serverCA *corev1.Secret, server string) (string, error) {
+	serverCACert, err := certs.DecodeCertPEM(serverCA.Data[TLSCrtDataName])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode CA Cert")
+	} else if serverCACert == nil {
+		return "", errors.New("certificate not found in config")
+	}
+
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return "kubeconfig is invalid", nil
+	}
+	if len(cfg.Clusters) == 0 {
+		return "kubeconfig has no cluster", nil
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Clusters)) {
+		cluster := cfg.Clusters[name]
+		if cluster.Server != server {
+			return fmt.Sprintf("server URL of cluster %s is %s instead of %s", name, cluster.Server, server), nil
+		}
+		caCert, err := certs.DecodeCertPEM(cluster.CertificateAuthorityData)
+		if err != nil || caCert == nil || !caCert.Equal(serverCACert) {
+			return fmt.Sprintf("CA of cluster %s doesn't match the APIServer CA", name), nil
+		}
+	}
+
+	return "", nil
+}
+
+// SetKubeconfigServer replaces the server URL of all the clusters in a given kubeconfig.
+func SetKubeconfigServer(data []byte, server string) ([]byte, error) {
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load kubeconfig")
+	}
+	if len(cfg.Clusters) == 0 {
+		return nil, errors.New("no cluster found in kubeconfig")
+	}
+
+	for _, cluster := range cfg.Clusters {
+		cluster.Server = server
+	}
+
+	out, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize config to yaml")
+	}
+
+	return out, nil
+}
+
 func GetAPIServerCA(ctx context.Context, c client.Client) ([]byte, error) {
 
 	privateCASecretName := os.Getenv(privateCASecNameEnv)
@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/utils/certutil"
+)
+
+const testServer = "http://connect-gateway.default.svc:8080/kubernetes/test-tunnel"
+
+func TestGenerateKubeconfigFromSecrets(t *testing.T) {
+	caCert, caKey, err := certutil.GenerateTestCertificate()
+	assert.NoError(t, err)
+	ccaCert, ccaKey, err := certutil.GenerateTestCertificate()
+	assert.NoError(t, err)
+
+	serverCA := &corev1.Secret{Data: map[string][]byte{TLSCrtDataName: caCert, TLSKeyDataName: caKey}}
+	clientClusterCA := &corev1.Secret{Data: map[string][]byte{TLSCrtDataName: ccaCert, TLSKeyDataName: ccaKey}}
+
+	data, err := GenerateKubeconfigFromSecrets("test-tunnel", serverCA, clientClusterCA, testServer)
+	assert.NoError(t, err)
+
+	cfg, err := clientcmd.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, testServer, cfg.Clusters["test-tunnel"].Server)
+	assert.Equal(t, caCert, cfg.Clusters["test-tunnel"].CertificateAuthorityData)
+	assert.NotEmpty(t, cfg.AuthInfos["test-tunnel-admin"].ClientCertificateData)
+
+	_, err = GenerateKubeconfigFromSecrets("test-tunnel", serverCA, &corev1.Secret{}, testServer)
+	assert.Error(t, err)
+}
+
+func TestKubeconfigDrift(t *testing.T) {
+	caCert, caKey, err := certutil.GenerateTestCertificate()
+	assert.NoError(t, err)
+	ccaCert, ccaKey, err := certutil.GenerateTestCertificate()
+	assert.NoError(t, err)
+	otherCACert, _, err := certutil.GenerateTestCertificate()
+	assert.NoError(t, err)
+
+	serverCA := &corev1.Secret{Data: map[string][]byte{TLSCrtDataName: caCert, TLSKeyDataName: caKey}}
+	clientClusterCA := &corev1.Secret{Data: map[string][]byte{TLSCrtDataName: ccaCert, TLSKeyDataName: ccaKey}}
+
+	data, err := GenerateKubeconfigFromSecrets("test-tunnel", serverCA, clientClusterCA, testServer)
+	assert.NoError(t, err)
+
+	drift, err := kubeconfigDrift(data, serverCA, testServer)
+	assert.NoError(t, err)
+	assert.Empty(t, drift)
+
+	// The server URL was overwritten, e.g. by Cluster-API.
+	overwritten, err := SetKubeconfigServer(data, "https://10.0.0.1:6443")
+	assert.NoError(t, err)
+	drift, err = kubeconfigDrift(overwritten, serverCA, testServer)
+	assert.NoError(t, err)
+	assert.Contains(t, drift, "server URL of cluster test-tunnel is https://10.0.0.1:6443")
+
+	// The APIServer CA was rotated.
+	drift, err = kubeconfigDrift(data, &corev1.Secret{Data: map[string][]byte{TLSCrtDataName: otherCACert}}, testServer)
+	assert.NoError(t, err)
+	assert.Contains(t, drift, "CA of cluster test-tunnel")
+
+	drift, err = kubeconfigDrift([]byte("test-data"), serverCA, testServer)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, drift)
+
+	_, err = kubeconfigDrift(data, &corev1.Secret{}, testServer)
+	assert.Error(t, err)
+}
+
+func TestSetKubeconfigServer(t *testing.T) {
+	kubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: imported
+  cluster:
+    server: https://10.0.0.1:6443
+contexts:
+- name: admin@imported
+  context:
+    cluster: imported
+    user: admin
+current-context: admin@imported
+users:
+- name: admin
+  user:
+    token: test-token
+`)
+
+	data, err := SetKubeconfigServer(kubeconfig, testServer)
+	assert.NoError(t, err)
+
+	cfg, err := clientcmd.Load(data)
+	assert.NoError(t, err)
+	assert.Equal(t, testServer, cfg.Clusters["imported"].Server)
+	assert.Equal(t, "test-token", cfg.AuthInfos["admin"].Token)
+
+	_, err = SetKubeconfigServer([]byte("apiVersion: v1\nkind: Config\n"), testServer)
+	assert.Error(t, err)
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package proxyutil
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	w := &ResponseRecorder{ResponseWriter: httptest.NewRecorder()}
+	assert.Equal(t, http.StatusOK, w.StatusCode())
+	w.WriteHeader(http.StatusContinue)
+	w.WriteHeader(http.StatusNotFound)
+	w.WriteHeader(http.StatusInternalServerError)
+	assert.Equal(t, http.StatusNotFound, w.StatusCode())
+
+	body := &bytes.Buffer{}
+	w = &ResponseRecorder{ResponseWriter: httptest.NewRecorder(), Body: body}
+	_, err := w.Write([]byte("ok"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.StatusCode())
+	assert.Equal(t, "ok", body.String())
+}
+
+func TestKubernetesRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/services/test-tunnel/monitoring/grafana:80/api/health?verbose=true", nil)
+	uri := KubernetesURI(map[string]string{
+		"tunnel_id":   "test-tunnel",
+		"namespace":   "monitoring",
+		"service":     "grafana",
+		"port":        "80",
+		"service_uri": "api/health",
+	})
+	kubeReq := KubernetesRequest(req, uri)
+	assert.Equal(t, "/api/v1/namespaces/monitoring/services/grafana:80/proxy/api/health?verbose=true", kubeReq.URL.RequestURI())
+	assert.Equal(t, "/services/test-tunnel/monitoring/grafana:80/api/health", req.URL.Path)
+
+	info, err := NewRequestInfo(kubeReq)
+	require.NoError(t, err)
+	assert.Equal(t, "services", info.Resource)
+	assert.Equal(t, "proxy", info.Subresource)
+	assert.Equal(t, "grafana:80", info.Name)
+}
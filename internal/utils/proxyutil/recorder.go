@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package proxyutil
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseRecorder records the status code, and optionally the body, of a response.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	// Body receives a copy of the response body, if set.
+	Body io.Writer
+	// OnResponseStarted is called, if set, with the status code once the response headers are sent.
+	OnResponseStarted func(code int)
+
+	code     int
+	hijacked bool
+}
+
+func (w *ResponseRecorder) WriteHeader(code int) {
+	// The informational responses, e.g. 100 Continue, precede the final one.
+	if w.code == 0 && (code >= http.StatusOK || code == http.StatusSwitchingProtocols) {
+		w.started(code)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *ResponseRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.started(http.StatusOK)
+	}
+	if w.Body != nil {
+		_, _ = w.Body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *ResponseRecorder) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+		if w.code == 0 && w.OnResponseStarted != nil {
+			w.OnResponseStarted(http.StatusSwitchingProtocols)
+		}
+	}
+	return conn, brw, err
+}
+
+func (w *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *ResponseRecorder) started(code int) {
+	w.code = code
+	if w.OnResponseStarted != nil {
+		w.OnResponseStarted(code)
+	}
+}
+
+// StatusCode returns the status code of the response. The upgraded requests write their response to the hijacked
+// connection, which is assumed to switch protocols.
+func (w *ResponseRecorder) StatusCode() int {
+	switch {
+	case w.code != 0:
+		return w.code
+	case w.hijacked:
+		return http.StatusSwitchingProtocols
+	default:
+		return http.StatusOK
+	}
+}
@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+// Package proxyutil provides the helpers shared by the handlers and middlewares of the proxied Kubernetes API and
+// service requests.
+package proxyutil
+
+import (
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+var requestInfoFactory = &request.RequestInfoFactory{
+	APIPrefixes:          sets.NewString("api", "apis"),
+	GrouplessAPIPrefixes: sets.NewString("api"),
+}
+
+// KubernetesURI returns the Kubernetes API URI, without leading slash, of a request with given route variables.
+// The requests to /kubernetes/{tunnel_id}/{kubernetes_uri} are proxied to that URI, and the requests to
+// /services/{tunnel_id}/{namespace}/{service}:{port}/{service_uri} are mapped to the service proxy URI of the
+// Kubernetes API, so that both are recorded alike.
+func KubernetesURI(vars map[string]string) string {
+	if service, ok := vars["service"]; ok {
+		return fmt.Sprintf("api/v1/namespaces/%s/services/%s:%s/proxy/%s",
+			vars["namespace"], service, vars["port"], vars["service_uri"])
+	}
+	return vars["kubernetes_uri"]
+}
+
+// KubernetesRequest returns a copy of a request to the gateway as received by the Kubernetes API server of the edge
+// cluster, at a given Kubernetes API URI.
+func KubernetesRequest(req *http.Request, kubernetesURI string) *http.Request {
+	kubeReq := req.Clone(req.Context())
+	kubeReq.URL.Path = "/" + kubernetesURI
+	kubeReq.URL.RawPath = ""
+	return kubeReq
+}
+
+// NewRequestInfo parses a request to the Kubernetes API, as returned by KubernetesRequest.
+func NewRequestInfo(kubeReq *http.Request) (*request.RequestInfo, error) {
+	return requestInfoFactory.NewRequestInfo(kubeReq)
+}
@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	clusterv1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/auth"
+)
+
+// projectIDSegments is the number of dash-separated segments of the project UUID that prefixes a tunnel ID.
+const projectIDSegments = 5
+
+var clusterconnectlog = logf.Log.WithName("clusterconnect-resource")
+
+// SetupClusterConnectWebhookWithManager registers the webhook for ClusterConnect in the manager.
+// When requireProjectID is set, ClusterConnect names must be prefixed with a project UUID,
+// as expected by the gateway when it authorizes users per project.
+func SetupClusterConnectWebhookWithManager(mgr ctrl.Manager, requireProjectID bool) error {
+	return ctrl.NewWebhookManagedBy(mgr, &clusterv1alpha1.ClusterConnect{}).
+		WithValidator(&ClusterConnectCustomValidator{RequireProjectID: requireProjectID}).
+		WithDefaulter(&ClusterConnectCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-cluster-edge-orchestrator-intel-com-v1alpha1-clusterconnect,mutating=true,failurePolicy=fail,sideEffects=None,groups=cluster.edge-orchestrator.intel.com,resources=clusterconnects,verbs=create;update,versions=v1alpha1,name=mclusterconnect-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ClusterConnectCustomDefaulter sets default values on the ClusterConnect resource when it is created or updated.
+type ClusterConnectCustomDefaulter struct{}
+
+// Default implements admission.Defaulter so a webhook will be registered for the ClusterConnect type.
+// References without a namespace default to the namespace of the ClusterRef,
+// which is where CAPI keeps the certificate and kubeconfig Secrets of a Cluster.
+func (d *ClusterConnectCustomDefaulter) Default(_ context.Context, cc *clusterv1alpha1.ClusterConnect) error {
+	clusterconnectlog.V(1).Info("Defaulting for ClusterConnect", "name", cc.GetName())
+
+	namespace := metav1.NamespaceDefault
+	if ref := cc.Spec.ClusterRef; ref != nil {
+		if ref.Namespace == "" {
+			ref.Namespace = namespace
+		}
+		namespace = ref.Namespace
+	}
+
+	for _, ref := range []*corev1.ObjectReference{cc.Spec.ServerCertRef, cc.Spec.ClientCertRef, cc.Spec.KubeconfigRef} {
+		if ref != nil && ref.Namespace == "" {
+			ref.Namespace = namespace
+		}
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-cluster-edge-orchestrator-intel-com-v1alpha1-clusterconnect,mutating=false,failurePolicy=fail,sideEffects=None,groups=cluster.edge-orchestrator.intel.com,resources=clusterconnects,verbs=create;update,versions=v1alpha1,name=vclusterconnect-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ClusterConnectCustomValidator validates the ClusterConnect resource when it is created or updated.
+type ClusterConnectCustomValidator struct {
+	// RequireProjectID enforces the <project UUID>-<name> format of the ClusterConnect name.
+	RequireProjectID bool
+}
+
+// ValidateCreate implements admission.Validator so a webhook will be registered for the ClusterConnect type.
+func (v *ClusterConnectCustomValidator) ValidateCreate(_ context.Context, cc *clusterv1alpha1.ClusterConnect) (admission.Warnings, error) {
+	clusterconnectlog.V(1).Info("Validation for ClusterConnect upon creation", "name", cc.GetName())
+
+	allErrs := v.validateName(cc.GetName())
+	allErrs = append(allErrs, validateSpec(&cc.Spec)...)
+	return nil, toInvalidError(cc, allErrs)
+}
+
+// ValidateUpdate implements admission.Validator so a webhook will be registered for the ClusterConnect type.
+// The name cannot change after creation, so only a changed spec is validated.
+// This keeps objects created before the webhook was enabled updatable, e.g. to remove their finalizer.
+func (v *ClusterConnectCustomValidator) ValidateUpdate(_ context.Context, oldCC, newCC *clusterv1alpha1.ClusterConnect) (admission.Warnings, error) {
+	clusterconnectlog.V(1).Info("Validation for ClusterConnect upon update", "name", newCC.GetName())
+
+	if !newCC.DeletionTimestamp.IsZero() || equality.Semantic.DeepEqual(oldCC.Spec, newCC.Spec) {
+		return nil, nil
+	}
+	return nil, toInvalidError(newCC, validateSpec(&newCC.Spec))
+}
+
+// ValidateDelete implements admission.Validator so a webhook will be registered for the ClusterConnect type.
+func (v *ClusterConnectCustomValidator) ValidateDelete(_ context.Context, _ *clusterv1alpha1.ClusterConnect) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *ClusterConnectCustomValidator) validateName(name string) field.ErrorList {
+	var allErrs field.ErrorList
+	namePath := field.NewPath("metadata", "name")
+
+	// The name is used as tunnel ID, which is part of the names of the derived resources.
+	if len(name) > auth.MaxTunnelIDLength {
+		allErrs = append(allErrs, field.TooLong(namePath, name, auth.MaxTunnelIDLength))
+	}
+
+	if v.RequireProjectID {
+		segments := strings.Split(name, "-")
+		if len(segments) <= projectIDSegments {
+			allErrs = append(allErrs, field.Invalid(namePath, name, "must be in the format <project UUID>-<name>"))
+		} else if _, err := uuid.Parse(strings.Join(segments[:projectIDSegments], "-")); err != nil {
+			allErrs = append(allErrs, field.Invalid(namePath, name, fmt.Sprintf("must start with a project UUID: %v", err)))
+		}
+	}
+
+	return allErrs
+}
+
+func validateSpec(spec *clusterv1alpha1.ClusterConnectSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	allErrs = append(allErrs, validateObjectReference(spec.ClusterRef, specPath.Child("clusterRef"))...)
+	allErrs = append(allErrs, validateObjectReference(spec.ServerCertRef, specPath.Child("serverCertRef"))...)
+	allErrs = append(allErrs, validateObjectReference(spec.ClientCertRef, specPath.Child("clientCertRef"))...)
+	allErrs = append(allErrs, validateObjectReference(spec.KubeconfigRef, specPath.Child("kubeconfigRef"))...)
+
+	return allErrs
+}
+
+func validateObjectReference(ref *corev1.ObjectReference, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if ref == nil {
+		return allErrs
+	}
+
+	if ref.Name == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("name"), "name of the referenced object must be set"))
+	}
+	if ref.Namespace == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), "namespace of the referenced object must be set"))
+	}
+
+	return allErrs
+}
+
+func toInvalidError(cc *clusterv1alpha1.ClusterConnect, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(clusterv1alpha1.GroupVersion.WithKind(clusterv1alpha1.ClusterConnectKind).GroupKind(), cc.GetName(), allErrs)
+}
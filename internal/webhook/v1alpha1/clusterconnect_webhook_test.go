@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1alpha1 "github.com/open-edge-platform/cluster-connect-gateway/api/v1alpha1"
+	"github.com/open-edge-platform/cluster-connect-gateway/internal/auth"
+)
+
+const testProjectID = "5e0c7a8b-4a1f-4f4e-9a59-0d4c3b1b2c3d"
+
+func newClusterConnect(name string, spec clusterv1alpha1.ClusterConnectSpec) *clusterv1alpha1.ClusterConnect {
+	return &clusterv1alpha1.ClusterConnect{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       spec,
+	}
+}
+
+func TestDefault(t *testing.T) {
+	defaulter := &ClusterConnectCustomDefaulter{}
+
+	t.Run("defaults to the ClusterRef namespace", func(t *testing.T) {
+		cc := newClusterConnect("test", clusterv1alpha1.ClusterConnectSpec{
+			ClusterRef:    &corev1.ObjectReference{Name: "cluster", Namespace: "capi"},
+			ServerCertRef: &corev1.ObjectReference{Name: "cluster-ca"},
+			ClientCertRef: &corev1.ObjectReference{Name: "cluster-cca", Namespace: "other"},
+		})
+		assert.NoError(t, defaulter.Default(context.Background(), cc))
+		assert.Equal(t, "capi", cc.Spec.ClusterRef.Namespace)
+		assert.Equal(t, "capi", cc.Spec.ServerCertRef.Namespace)
+		assert.Equal(t, "other", cc.Spec.ClientCertRef.Namespace)
+	})
+
+	t.Run("defaults to the default namespace", func(t *testing.T) {
+		cc := newClusterConnect("test", clusterv1alpha1.ClusterConnectSpec{
+			ClusterRef:    &corev1.ObjectReference{Name: "cluster"},
+			ClientCertRef: &corev1.ObjectReference{Name: "cluster-cca"},
+			KubeconfigRef: &corev1.ObjectReference{Name: "cluster-kubeconfig"},
+		})
+		assert.NoError(t, defaulter.Default(context.Background(), cc))
+		assert.Equal(t, metav1.NamespaceDefault, cc.Spec.ClusterRef.Namespace)
+		assert.Equal(t, metav1.NamespaceDefault, cc.Spec.ClientCertRef.Namespace)
+		assert.Equal(t, metav1.NamespaceDefault, cc.Spec.KubeconfigRef.Namespace)
+		assert.Nil(t, cc.Spec.ServerCertRef)
+	})
+}
+
+func TestValidateCreate(t *testing.T) {
+	validRefs := clusterv1alpha1.ClusterConnectSpec{
+		ServerCertRef: &corev1.ObjectReference{Name: "cluster-ca", Namespace: "default"},
+		ClientCertRef: &corev1.ObjectReference{Name: "cluster-cca", Namespace: "default"},
+	}
+
+	tests := []struct {
+		name             string
+		ccName           string
+		spec             clusterv1alpha1.ClusterConnectSpec
+		requireProjectID bool
+		wantErrFields    []string
+	}{
+		{
+			name:   "valid",
+			ccName: "sample",
+			spec:   validRefs,
+		},
+		{
+			name:   "longest name",
+			ccName: strings.Repeat("a", auth.MaxTunnelIDLength),
+		},
+		{
+			name:          "name too long",
+			ccName:        strings.Repeat("a", auth.MaxTunnelIDLength+1),
+			wantErrFields: []string{"metadata.name"},
+		},
+		{
+			name:             "valid project ID",
+			ccName:           testProjectID + "-cluster",
+			requireProjectID: true,
+		},
+		{
+			name:             "missing project ID",
+			ccName:           "sample",
+			requireProjectID: true,
+			wantErrFields:    []string{"metadata.name"},
+		},
+		{
+			name:             "invalid project ID",
+			ccName:           "not-a-valid-project-id-cluster",
+			requireProjectID: true,
+			wantErrFields:    []string{"metadata.name"},
+		},
+		{
+			name:             "project ID without cluster name",
+			ccName:           testProjectID,
+			requireProjectID: true,
+			wantErrFields:    []string{"metadata.name"},
+		},
+		{
+			name:   "reference without name and namespace",
+			ccName: "sample",
+			spec: clusterv1alpha1.ClusterConnectSpec{
+				ClusterRef:    &corev1.ObjectReference{},
+				ServerCertRef: &corev1.ObjectReference{Name: "cluster-ca"},
+			},
+			wantErrFields: []string{"spec.clusterRef.name", "spec.clusterRef.namespace", "spec.serverCertRef.namespace"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &ClusterConnectCustomValidator{RequireProjectID: tt.requireProjectID}
+			_, err := validator.ValidateCreate(context.Background(), newClusterConnect(tt.ccName, tt.spec))
+			if len(tt.wantErrFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, apierrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+			var fields []string
+			for _, cause := range err.(apierrors.APIStatus).Status().Details.Causes {
+				fields = append(fields, cause.Field)
+			}
+			assert.Equal(t, tt.wantErrFields, fields)
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	validator := &ClusterConnectCustomValidator{RequireProjectID: true}
+
+	// Objects created before the webhook was enabled can still be updated as long as the spec is unchanged.
+	oldCC := newClusterConnect("sample", clusterv1alpha1.ClusterConnectSpec{
+		ServerCertRef: &corev1.ObjectReference{Name: "cluster-ca"},
+	})
+	newCC := oldCC.DeepCopy()
+	newCC.Finalizers = nil
+	_, err := validator.ValidateUpdate(context.Background(), oldCC, newCC)
+	assert.NoError(t, err)
+
+	newCC.Spec.ClientCertRef = &corev1.ObjectReference{Name: "cluster-cca", Namespace: "default"}
+	_, err = validator.ValidateUpdate(context.Background(), oldCC, newCC)
+	assert.True(t, apierrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+
+	now := metav1.Now()
+	newCC.DeletionTimestamp = &now
+	_, err = validator.ValidateUpdate(context.Background(), oldCC, newCC)
+	assert.NoError(t, err)
+}
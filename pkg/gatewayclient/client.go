@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+// Package gatewayclient provides a client for the administrative API of the cluster connection gateway.
+//
+// The administrative API is served by each gateway replica and describes the sessions held by that replica only.
+package gatewayclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// AdminPathPrefix is the path prefix of the administrative API.
+	AdminPathPrefix = "/admin/v1"
+
+	defaultTimeout = 10 * time.Second
+	maxErrorLength = 1024
+)
+
+// ErrNotFound is returned when the gateway replica doesn't hold any session for a given tunnel.
+var ErrNotFound = errors.New("tunnel not found")
+
+// Session describes a connect-agent session held by a gateway replica.
+type Session struct {
+	RemoteAddress  string    `json:"remoteAddress,omitempty"`
+	AgentVersion   string    `json:"agentVersion,omitempty"`
+	GatewayReplica string    `json:"gatewayReplica"`
+	ConnectedAt    time.Time `json:"connectedAt"`
+}
+
+// Tunnel describes a tunnel with active sessions on a gateway replica.
+type Tunnel struct {
+	TunnelID string    `json:"tunnelID"`
+	Sessions []Session `json:"sessions"`
+
+	// CachedClients is the number of Kubernetes API clients cached by the gateway replica for the tunnel.
+	CachedClients int `json:"cachedClients"`
+}
+
+// TunnelList is the list of tunnels with active sessions on a gateway replica.
+type TunnelList struct {
+	GatewayReplica string   `json:"gatewayReplica"`
+	Tunnels        []Tunnel `json:"tunnels"`
+}
+
+// Client is a client for the administrative API of the gateway.
+type Client struct {
+	baseURL    *url.URL
+	token      string
+	httpClient *http.Client
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to send the requests, e.g. to configure TLS.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New creates a new Client for the gateway at a given base URL, authenticating with a given bearer token.
+func New(baseURL, token string, options ...Option) (*Client, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gateway URL %s: %w", baseURL, err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid gateway URL %s: unsupported scheme %q", baseURL, parsedURL.Scheme)
+	}
+	if token == "" {
+		return nil, errors.New("token must not be empty")
+	}
+
+	c := &Client{
+		baseURL:    parsedURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c, nil
+}
+
+// ListTunnels returns the tunnels with active sessions on the gateway replica.
+func (c *Client) ListTunnels(ctx context.Context) (*TunnelList, error) {
+	list := &TunnelList{}
+	if err := c.do(ctx, http.MethodGet, nil, list, "tunnels"); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// GetTunnel returns the active sessions of a given tunnel on the gateway replica.
+func (c *Client) GetTunnel(ctx context.Context, tunnelID string) (*Tunnel, error) {
+	tunnel := &Tunnel{}
+	if err := c.do(ctx, http.MethodGet, nil, tunnel, "tunnels", tunnelID); err != nil {
+		return nil, err
+	}
+	return tunnel, nil
+}
+
+// Disconnect closes the sessions of a given tunnel on the gateway replica with a given reason,
+// which is recorded in the ClusterConnect status.
+func (c *Client) Disconnect(ctx context.Context, tunnelID, reason string) error {
+	query := url.Values{}
+	if reason != "" {
+		query.Set("reason", reason)
+	}
+	return c.do(ctx, http.MethodDelete, query, nil, "tunnels", tunnelID, "sessions")
+}
+
+// FlushClient removes the cached Kubernetes API clients, certificates and kubeconfig of a given tunnel
+// from the gateway replica.
+func (c *Client) FlushClient(ctx context.Context, tunnelID string) error {
+	return c.do(ctx, http.MethodDelete, nil, nil, "tunnels", tunnelID, "client")
+}
+
+func (c *Client) do(ctx context.Context, method string, query url.Values, out any, path ...string) error {
+	// JoinPath expects escaped segments, so that a tunnel ID containing a slash stays a single segment.
+	segments := []string{AdminPathPrefix}
+	for _, segment := range path {
+		segments = append(segments, url.PathEscape(segment))
+	}
+	reqURL := c.baseURL.JoinPath(segments...)
+	reqURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return fmt.Errorf("%s %s failed with status %d: %s", method, reqURL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+	case out == nil:
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, reqURL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: (C) 2025 Intel Corporation
+// SPDX-License-Identifier: Apache-2.0
+
+package gatewayclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("http://connect-gateway.default.svc:8080", "token")
+	assert.NoError(t, err)
+
+	_, err = New("ws://connect-gateway.default.svc:8080", "token")
+	assert.Error(t, err)
+
+	_, err = New("http://connect-gateway.default.svc:8080", "")
+	assert.Error(t, err)
+}
+
+func TestDisconnect(t *testing.T) {
+	var gotMethod, gotPath, gotReason, gotAuth string
+	gateway := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotMethod = req.Method
+		gotPath = req.URL.EscapedPath()
+		gotReason = req.URL.Query().Get("reason")
+		gotAuth = req.Header.Get("Authorization")
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer gateway.Close()
+
+	c, err := New(gateway.URL+"/base", "token")
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Disconnect(context.Background(), "test-tunnel", "deleted"))
+	assert.Equal(t, http.MethodDelete, gotMethod)
+	assert.Equal(t, "/base/admin/v1/tunnels/test-tunnel/sessions", gotPath)
+	assert.Equal(t, "deleted", gotReason)
+	assert.Equal(t, "Bearer token", gotAuth)
+
+	// The path segments are escaped once.
+	assert.NoError(t, c.Disconnect(context.Background(), "test/tunnel%", "deleted"))
+	assert.Equal(t, "/base/admin/v1/tunnels/test%2Ftunnel%25/sessions", gotPath)
+}
+
+func TestErrors(t *testing.T) {
+	status := http.StatusNotFound
+	gateway := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Error(rw, "failure", status)
+	}))
+	defer gateway.Close()
+
+	c, err := New(gateway.URL, "token")
+	assert.NoError(t, err)
+
+	_, err = c.GetTunnel(context.Background(), "test-tunnel")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	status = http.StatusInternalServerError
+	_, err = c.ListTunnels(context.Background())
+	assert.ErrorContains(t, err, "failed with status 500: failure")
+}